@@ -0,0 +1,141 @@
+package escpos
+
+import (
+	"sync/atomic"
+)
+
+// ASBFlag selects the statuses reported by Automatic Status Back.
+type ASBFlag uint8
+
+const (
+	ASBDrawer ASBFlag = 1 << iota
+	ASBOnline
+	ASBError
+	ASBPaper
+
+	ASBAll = ASBDrawer | ASBOnline | ASBError | ASBPaper
+)
+
+// ASBStatus is the printer status decoded from an Automatic Status Back frame.
+type ASBStatus struct {
+	// DrawerOpen reports pin 3 of the drawer kick-out connector high, which
+	// on most drawers means the drawer is open.
+	DrawerOpen bool
+
+	// Offline reports the printer offline.
+	Offline bool
+
+	// CoverOpen reports the cover open.
+	CoverOpen bool
+
+	// FeedButtonFeeding reports paper being fed with the feed button.
+	FeedButtonFeeding bool
+
+	// MechanicalError, CutterError, UnrecoverableError and
+	// AutoRecoverableError have the meaning given in ErrorCause.
+	MechanicalError      bool
+	CutterError          bool
+	UnrecoverableError   bool
+	AutoRecoverableError bool
+
+	// PaperNearEnd reports the paper roll near its end.
+	PaperNearEnd bool
+
+	// PaperEnd reports no paper.
+	PaperEnd bool
+}
+
+// ASBEvent is delivered whenever the printer reports a status change.
+type ASBEvent struct {
+	// Status is the new status.
+	Status ASBStatus
+
+	// Previous is the status before the change; it is the zero value for
+	// the first event after EnableASB.
+	Previous ASBStatus
+}
+
+// decode a 4-byte ASB frame
+func decodeASB(f [4]byte) ASBStatus {
+	return ASBStatus{
+		DrawerOpen:           f[0]&0x04 != 0,
+		Offline:              f[0]&0x08 != 0,
+		CoverOpen:            f[0]&0x20 != 0,
+		FeedButtonFeeding:    f[0]&0x40 != 0,
+		MechanicalError:      f[1]&0x04 != 0,
+		CutterError:          f[1]&0x08 != 0,
+		UnrecoverableError:   f[1]&0x20 != 0,
+		AutoRecoverableError: f[1]&0x40 != 0,
+		PaperNearEnd:         f[2]&0x03 != 0,
+		PaperEnd:             f[2]&0x0c != 0,
+	}
+}
+
+// EnableASB enables Automatic Status Back (GS a) for the statuses in flags
+// and returns a channel delivering an event for every status change. A
+// background goroutine reads everything the printer sends, so status queries
+// and other answers keep working alongside.
+//
+// The channel must be drained; it is closed by DisableASB, by Close or when
+// reading from the printer fails. Call Close when done with the printer.
+func (e *Escpos) EnableASB(flags ASBFlag) (<-chan ASBEvent, error) {
+	if e.closed {
+		return nil, ErrClosed
+	}
+	if err := e.DisableASB(); err != nil {
+		return nil, err
+	}
+
+	e.startDemux()
+
+	// forget frames nobody was listening to
+	for len(e.demux.frames) > 0 {
+		<-e.demux.frames
+	}
+
+	atomic.StoreInt32(&e.demux.asb, 1)
+	if err := e.command("automatic status back", []byte{GS, 'a', byte(flags)}); err != nil {
+		return nil, err
+	}
+
+	events := make(chan ASBEvent)
+	stop := make(chan struct{})
+	e.asbStop = stop
+	go func(frames <-chan ASBStatus) {
+		defer close(events)
+		var prev ASBStatus
+		for {
+			select {
+			case s, ok := <-frames:
+				if !ok {
+					return
+				}
+				select {
+				case events <- ASBEvent{Status: s, Previous: prev}:
+				case <-stop:
+					return
+				}
+				prev = s
+			case <-stop:
+				return
+			}
+		}
+	}(e.demux.frames)
+
+	return events, nil
+}
+
+// DisableASB disables Automatic Status Back and closes the event channel
+// returned by EnableASB. It does nothing if ASB is not enabled.
+func (e *Escpos) DisableASB() error {
+	if e.asbStop == nil {
+		return nil
+	}
+	close(e.asbStop)
+	e.asbStop = nil
+	if err := e.command("automatic status back", []byte{GS, 'a', 0}); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&e.demux.asb, 0)
+	return nil
+}
@@ -0,0 +1,137 @@
+package escpos
+
+import "fmt"
+
+// HRIPosition is where the human readable interpretation of a barcode is
+// printed.
+type HRIPosition uint8
+
+const (
+	HRINotPrinted HRIPosition = iota + 1
+	HRIAbove
+	HRIBelow
+	HRIBoth
+)
+
+// BarcodeOptions controls the appearance of barcodes. Zero fields keep the
+// current setting.
+type BarcodeOptions struct {
+	// Height is the bar height in dots, 1-255 (GS h).
+	Height int
+
+	// Width is the module width in dots, 2-6 (GS w).
+	Width int
+
+	// HRI is where the human readable text is printed (GS H).
+	HRI HRIPosition
+
+	// HRIFont is the font of the human readable text, "A", "B" or "C"
+	// (GS f).
+	HRIFont string
+}
+
+// printer settings after ESC @
+var defaultBarcodeOptions = BarcodeOptions{Height: 162, Width: 3, HRI: HRINotPrinted, HRIFont: "A"}
+
+// validate the options, replacing invalid ones in lenient mode
+func (e *Escpos) checkBarcodeOptions(o BarcodeOptions) (BarcodeOptions, error) {
+	if o.Height < 0 || o.Height > 255 {
+		if err := e.invalid("barcode height", fmt.Sprint(o.Height)); err != nil {
+			return o, err
+		}
+		o.Height = clampInt(o.Height, 1, 255)
+	}
+	if o.Width != 0 && (o.Width < 2 || o.Width > 6) {
+		if err := e.invalid("barcode width", fmt.Sprint(o.Width)); err != nil {
+			return o, err
+		}
+		o.Width = clampInt(o.Width, 2, 6)
+	}
+	if o.HRI > HRIBoth {
+		if err := e.invalid("HRI position", fmt.Sprint(o.HRI)); err != nil {
+			return o, err
+		}
+		o.HRI = 0
+	}
+	switch o.HRIFont {
+	case "", "A", "B", "C":
+	default:
+		if err := e.invalid("HRI font", o.HRIFont); err != nil {
+			return o, err
+		}
+		o.HRIFont = ""
+	}
+	return o, nil
+}
+
+// send the options that differ from the current settings
+func (e *Escpos) sendBarcodeOptions(o BarcodeOptions) error {
+	if o.Height != 0 && o.Height != e.barcode.Height {
+		if err := e.command("barcode height", []byte{GS, 'h', byte(o.Height)}); err != nil {
+			return err
+		}
+		e.barcode.Height = o.Height
+	}
+	if o.Width != 0 && o.Width != e.barcode.Width {
+		if err := e.command("barcode width", []byte{GS, 'w', byte(o.Width)}); err != nil {
+			return err
+		}
+		e.barcode.Width = o.Width
+	}
+	if o.HRI != 0 && o.HRI != e.barcode.HRI {
+		if err := e.command("HRI position", []byte{GS, 'H', byte(o.HRI - 1)}); err != nil {
+			return err
+		}
+		e.barcode.HRI = o.HRI
+	}
+	if o.HRIFont != "" && o.HRIFont != e.barcode.HRIFont {
+		if err := e.command("HRI font", []byte{GS, 'f', o.HRIFont[0] - 'A'}); err != nil {
+			return err
+		}
+		e.barcode.HRIFont = o.HRIFont
+	}
+	return nil
+}
+
+// SetBarcodeOptions changes the barcode settings for all following barcodes.
+func (e *Escpos) SetBarcodeOptions(o BarcodeOptions) error {
+	o, err := e.checkBarcodeOptions(o)
+	if err != nil {
+		return err
+	}
+	return e.sendBarcodeOptions(o)
+}
+
+// Barcode sends a barcode to the printer, at the current alignment. The data
+// is checked with ValidateBarcode before anything is sent. opts, if not nil,
+// applies to this barcode only: the previous settings are restored
+// afterwards.
+func (e *Escpos) Barcode(barcode string, format BarcodeFormat, opts *BarcodeOptions) error {
+	if format < BarcodeFormatUPC_A || format > BarcodeFormatGS1DataBarExpanded {
+		return &InvalidArgumentError{Command: "barcode", Value: fmt.Sprint(format)}
+	}
+	if !e.profile.SupportsBarcode(format) {
+		return e.unsupported("barcode")
+	}
+	barcode, err := ValidateBarcode(format, barcode)
+	if err != nil {
+		return err
+	}
+
+	previous := e.barcode
+	if opts != nil {
+		o, err := e.checkBarcodeOptions(*opts)
+		if err != nil {
+			return err
+		}
+		if err := e.sendBarcodeOptions(o); err != nil {
+			return err
+		}
+	}
+
+	// write barcode, function B: GS k m n d1...dn
+	if err := e.command("barcode", append([]byte{GS, 'k', byte(format), byte(len(barcode))}, barcode...)); err != nil {
+		return err
+	}
+	return e.sendBarcodeOptions(previous)
+}
@@ -0,0 +1,240 @@
+package escpos
+
+import (
+	"fmt"
+	"strings"
+)
+
+var barcodeNames = map[BarcodeFormat]string{
+	BarcodeFormatUPC_A:                     "UPC-A",
+	BarcodeFormatUPC_E:                     "UPC-E",
+	BarcodeFormatEAN13:                     "EAN-13",
+	BarcodeFormatEAN8:                      "EAN-8",
+	BarcodeFormatCode39:                    "Code39",
+	BarcodeFormatITF:                       "ITF",
+	BarcodeFormatCodabar:                   "Codabar",
+	BarcodeFormatCode93:                    "Code93",
+	BarcodeFormatCode128:                   "Code128",
+	BarcodeFormatGS1_128:                   "GS1-128",
+	BarcodeFormatGS1DataBarOmnidirectional: "GS1 DataBar Omnidirectional",
+	BarcodeFormatGS1DataBarTruncated:       "GS1 DataBar Truncated",
+	BarcodeFormatGS1DataBarLimited:         "GS1 DataBar Limited",
+	BarcodeFormatGS1DataBarExpanded:        "GS1 DataBar Expanded",
+}
+
+func (f BarcodeFormat) String() string {
+	if name, ok := barcodeNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("BarcodeFormat(%d)", int(f))
+}
+
+// characters allowed in the data of each symbology, besides digits
+const (
+	code39Chars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ -.$/+%"
+	codabarChars = "-$:/.+"
+	codabarStart = "ABCDabcd"
+)
+
+// ValidateBarcode checks data against the rules of the symbology and returns
+// it as it will be sent to the printer: the check digit of UPC, EAN and ITF
+// barcodes is appended when omitted, a given check digit is verified, and
+// plain Code128 and GS1-128 data is encoded with EncodeCode128.
+// Invalid data is reported as a *BarcodeError.
+func ValidateBarcode(format BarcodeFormat, data string) (string, error) {
+	fail := func(reason string, args ...interface{}) (string, error) {
+		return "", &BarcodeError{Format: format, Data: data, Reason: fmt.Sprintf(reason, args...)}
+	}
+	if data == "" {
+		return fail("no data")
+	}
+	if len(data) > 255 {
+		return fail("%d bytes, at most 255 fit", len(data))
+	}
+
+	switch format {
+	case BarcodeFormatUPC_A:
+		return checkDigits(format, data, 11)
+	case BarcodeFormatEAN13:
+		return checkDigits(format, data, 12)
+	case BarcodeFormatEAN8:
+		return checkDigits(format, data, 7)
+
+	case BarcodeFormatUPC_E:
+		if i := notDigit(data); i >= 0 {
+			return fail("%q at position %d is not a digit", data[i], i+1)
+		}
+		// GS k only encodes number system 0
+		if len(data) > 6 && data[0] != '0' {
+			return fail("number system %c, must be 0", data[0])
+		}
+		switch len(data) {
+		case 6, 7:
+			// the printer adds the number system and check digit
+			return data, nil
+		case 8:
+			// the check digit is that of the expanded UPC-A code
+			upcA := data[:1] + expandUPCE(data[1:7])
+			if c := checkDigit(upcA); c != data[7] {
+				return fail("check digit is %c, not %c", c, data[7])
+			}
+			return data, nil
+		case 11, 12:
+			if _, ok := compressUPCA(data[1:11]); !ok {
+				return fail("UPC-A code %s cannot be compressed to UPC-E", data[:11])
+			}
+			return checkDigits(format, data, 11)
+		}
+		return fail("%d digits, must be 6, 7, 8, 11 or 12", len(data))
+
+	case BarcodeFormatITF:
+		if i := notDigit(data); i >= 0 {
+			return fail("%q at position %d is not a digit", data[i], i+1)
+		}
+		if len(data) < 2 {
+			return fail("%d digit, at least 2 are needed", len(data))
+		}
+		// digits are encoded in pairs: an odd count gets a check digit
+		if len(data)%2 != 0 {
+			return data + string(checkDigit(data)), nil
+		}
+		return data, nil
+
+	case BarcodeFormatCode39:
+		body := data
+		if len(body) >= 2 && body[0] == '*' && body[len(body)-1] == '*' {
+			body = body[1 : len(body)-1]
+		}
+		for i := 0; i < len(body); i++ {
+			if !isDigit(body[i]) && strings.IndexByte(code39Chars, body[i]) < 0 {
+				return fail("%q is not a Code39 character", body[i])
+			}
+		}
+		return data, nil
+
+	case BarcodeFormatCodabar:
+		if len(data) < 3 {
+			return fail("%d characters, at least a start, data and stop character are needed", len(data))
+		}
+		if strings.IndexByte(codabarStart, data[0]) < 0 {
+			return fail("start character %q must be A, B, C or D", data[0])
+		}
+		if strings.IndexByte(codabarStart, data[len(data)-1]) < 0 {
+			return fail("stop character %q must be A, B, C or D", data[len(data)-1])
+		}
+		for i := 1; i < len(data)-1; i++ {
+			if !isDigit(data[i]) && strings.IndexByte(codabarChars, data[i]) < 0 {
+				return fail("%q at position %d is not a Codabar character", data[i], i+1)
+			}
+		}
+		return data, nil
+
+	case BarcodeFormatCode128, BarcodeFormatGS1_128:
+		// data starting with a code set selection is already encoded
+		if len(data) >= 2 && data[0] == '{' && strings.IndexByte("ABC", data[1]) >= 0 {
+			return data, nil
+		}
+		return EncodeCode128(data, format == BarcodeFormatGS1_128)
+
+	case BarcodeFormatCode93, BarcodeFormatGS1DataBarExpanded:
+		for i := 0; i < len(data); i++ {
+			if data[i] > 127 {
+				return fail("byte 0x%02x at position %d is not ASCII", data[i], i+1)
+			}
+		}
+		return data, nil
+
+	case BarcodeFormatGS1DataBarOmnidirectional, BarcodeFormatGS1DataBarTruncated, BarcodeFormatGS1DataBarLimited:
+		// a GTIN-14, sent without its check digit
+		if i := notDigit(data); i >= 0 {
+			return fail("%q at position %d is not a digit", data[i], i+1)
+		}
+		code, err := checkDigits(format, data, 13)
+		if err != nil {
+			return "", err
+		}
+		if format == BarcodeFormatGS1DataBarLimited && code[0] > '1' {
+			return fail("first digit %c, must be 0 or 1", code[0])
+		}
+		return code[:13], nil
+	}
+
+	return fail("unknown symbology")
+}
+
+// check a code of n digits plus a check digit, appending the check digit
+// when omitted
+func checkDigits(format BarcodeFormat, data string, n int) (string, error) {
+	if i := notDigit(data); i >= 0 {
+		return "", &BarcodeError{Format: format, Data: data, Reason: fmt.Sprintf("%q at position %d is not a digit", data[i], i+1)}
+	}
+	switch len(data) {
+	case n:
+		return data + string(checkDigit(data)), nil
+	case n + 1:
+		if c := checkDigit(data[:n]); c != data[n] {
+			return "", &BarcodeError{Format: format, Data: data, Reason: fmt.Sprintf("check digit is %c, not %c", c, data[n])}
+		}
+		return data, nil
+	}
+	return "", &BarcodeError{Format: format, Data: data, Reason: fmt.Sprintf("%d digits, must be %d, or %d with the check digit", len(data), n, n+1)}
+}
+
+// modulo 10 check digit of UPC, EAN, ITF and GTIN codes: digits are weighted
+// 3 and 1 alternately, starting with 3 at the right
+func checkDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// expand the 6 digits of a UPC-E code to the 10 digit manufacturer and
+// product code of UPC-A
+func expandUPCE(e string) string {
+	switch e[5] {
+	case '0', '1', '2':
+		return e[0:2] + e[5:6] + "0000" + e[2:5]
+	case '3':
+		return e[0:3] + "00000" + e[3:5]
+	case '4':
+		return e[0:4] + "00000" + e[4:5]
+	}
+	return e[0:5] + "0000" + e[5:6]
+}
+
+// compress the 10 digit manufacturer and product code of UPC-A to the 6
+// digits of UPC-E, if possible
+func compressUPCA(a string) (string, bool) {
+	m, p := a[:5], a[5:]
+	switch {
+	case m[2] <= '2' && m[3:] == "00" && p[:2] == "00":
+		return m[:2] + p[2:] + m[2:3], true
+	case m[3:] == "00" && p[:3] == "000":
+		return m[:3] + p[3:] + "3", true
+	case m[4] == '0' && p[:4] == "0000":
+		return m[:4] + p[4:] + "4", true
+	case p[:4] == "0000" && p[4] >= '5':
+		return m + p[4:], true
+	}
+	return "", false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// index of the first byte that is not a digit, or -1
+func notDigit(s string) int {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return i
+		}
+	}
+	return -1
+}
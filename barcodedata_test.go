@@ -0,0 +1,101 @@
+package escpos
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateBarcode(t *testing.T) {
+	for _, tc := range []struct {
+		format BarcodeFormat
+		data   string
+		want   string // "" for invalid data
+	}{
+		// check digits appended or verified
+		{BarcodeFormatUPC_A, "03600029145", "036000291452"},
+		{BarcodeFormatUPC_A, "036000291452", "036000291452"},
+		{BarcodeFormatUPC_A, "036000291453", ""},
+		{BarcodeFormatUPC_A, "0360002914", ""},
+		{BarcodeFormatUPC_A, "0360002914x", ""},
+		{BarcodeFormatEAN13, "400638133393", "4006381333931"},
+		{BarcodeFormatEAN13, "4006381333931", "4006381333931"},
+		{BarcodeFormatEAN13, "4006381333932", ""},
+		{BarcodeFormatEAN8, "9638507", "96385074"},
+		{BarcodeFormatEAN8, "96385074", "96385074"},
+		{BarcodeFormatEAN8, "96385075", ""},
+
+		// UPC-E, number system 0 only, check digit of the expanded UPC-A
+		{BarcodeFormatUPC_E, "425261", "425261"},
+		{BarcodeFormatUPC_E, "0425261", "0425261"},
+		{BarcodeFormatUPC_E, "1425261", ""},
+		{BarcodeFormatUPC_E, "04252614", "04252614"},
+		{BarcodeFormatUPC_E, "04252615", ""},
+		{BarcodeFormatUPC_E, "14252614", ""},
+		{BarcodeFormatUPC_E, "04210000526", "042100005264"},
+		{BarcodeFormatUPC_E, "042100005264", "042100005264"},
+		{BarcodeFormatUPC_E, "142100005264", ""},
+		{BarcodeFormatUPC_E, "036000291452", ""},
+		{BarcodeFormatUPC_E, "42526", ""},
+
+		// ITF digits come in pairs
+		{BarcodeFormatITF, "1234567", "12345670"},
+		{BarcodeFormatITF, "123456", "123456"},
+		{BarcodeFormatITF, "1", ""},
+
+		{BarcodeFormatCode39, "*CODE 39*", "*CODE 39*"},
+		{BarcodeFormatCode39, "code39", ""},
+		{BarcodeFormatCodabar, "A40156B", "A40156B"},
+		{BarcodeFormatCodabar, "40156", ""},
+		{BarcodeFormatCode93, "Code93", "Code93"},
+		{BarcodeFormatCode93, "caf\xe9", ""},
+
+		// Code128 data already encoded is passed through
+		{BarcodeFormatCode128, "{Babc", "{Babc"},
+		{BarcodeFormatCode128, "abc", "{Babc"},
+
+		// GS1 DataBar takes a GTIN-14 without its check digit
+		{BarcodeFormatGS1DataBarOmnidirectional, "0001234560001", "0001234560001"},
+		{BarcodeFormatGS1DataBarOmnidirectional, "00012345600012", "0001234560001"},
+		{BarcodeFormatGS1DataBarOmnidirectional, "00012345600013", ""},
+		{BarcodeFormatGS1DataBarLimited, "2001234560001", ""},
+
+		{BarcodeFormatCode39, "", ""},
+		{BarcodeFormatCode93, strings.Repeat("A", 256), ""},
+		{BarcodeFormat(99), "1", ""},
+	} {
+		got, err := ValidateBarcode(tc.format, tc.data)
+		if tc.want == "" {
+			var berr *BarcodeError
+			if !errors.As(err, &berr) {
+				t.Errorf("%v %q: got %q, %v, want a *BarcodeError", tc.format, tc.data, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%v %q: got %q, %v, want %q", tc.format, tc.data, got, err, tc.want)
+		}
+	}
+}
+
+func TestUPCE(t *testing.T) {
+	// one code for each way of compressing the manufacturer and product code
+	for _, tc := range []struct {
+		upcE, upcA string
+	}{
+		{"425261", "4210000526"},
+		{"123453", "1230000045"},
+		{"123454", "1234000005"},
+		{"123457", "1234500007"},
+	} {
+		if got := expandUPCE(tc.upcE); got != tc.upcA {
+			t.Errorf("expandUPCE(%q) = %q, want %q", tc.upcE, got, tc.upcA)
+		}
+		if got, ok := compressUPCA(tc.upcA); !ok || got != tc.upcE {
+			t.Errorf("compressUPCA(%q) = %q, %v, want %q", tc.upcA, got, ok, tc.upcE)
+		}
+	}
+	if got, ok := compressUPCA("3600029145"); ok {
+		t.Errorf("compressUPCA(%q) = %q, want no UPC-E code", "3600029145", got)
+	}
+}
@@ -0,0 +1,102 @@
+package escpos
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/david-yappeter/escpos/raster"
+)
+
+// BitImageMode is the density of ESC * bit images.
+type BitImageMode uint8
+
+const (
+	// 8 dots per column, half horizontal and a third of vertical density
+	BitImage8Single BitImageMode = 0
+	// 8 dots per column, a third of vertical density
+	BitImage8Double BitImageMode = 1
+	// 24 dots per column, half horizontal density
+	BitImage24Single BitImageMode = 32
+	// 24 dots per column, full density
+	BitImage24Double BitImageMode = 33
+)
+
+// dots per column and size of a dot, in full density dots, of each mode
+var bitImageModes = map[BitImageMode]struct{ dots, dotWidth, dotHeight int }{
+	BitImage8Single:  {8, 2, 3},
+	BitImage8Double:  {8, 1, 3},
+	BitImage24Single: {24, 2, 1},
+	BitImage24Double: {24, 1, 1},
+}
+
+// line spacing, in motion units, that feeds exactly one bit image band
+const bitImageLineSpacing = 24
+
+// SetLineSpacing sets the line spacing in motion units (ESC 3).
+func (e *Escpos) SetLineSpacing(n int) error {
+	if n < 0 || n > 255 {
+		if err := e.invalid("line spacing", fmt.Sprint(n)); err != nil {
+			return err
+		}
+		n = clampInt(n, 0, 255)
+	}
+	if err := e.command("line spacing", []byte{ESC, '3', byte(n)}); err != nil {
+		return err
+	}
+	e.lineSpacing = n
+	return nil
+}
+
+// SetDefaultLineSpacing restores the default line spacing (ESC 2).
+func (e *Escpos) SetDefaultLineSpacing() error {
+	if err := e.command("line spacing", []byte{ESC, '2'}); err != nil {
+		return err
+	}
+	e.lineSpacing = -1
+	return nil
+}
+
+// BitImage prints an image with ESC * column bit images, converted as set by
+// SetConverter and SetDither. It works on printers without GS v 0 raster
+// images and needs no page mode. The image is printed in bands of 8 or 24
+// rows with the line spacing set to feed one band, and the line spacing is
+// restored afterwards. The lower density modes print dots two wide, three
+// tall or both, so images are scaled down to keep their size and aspect
+// ratio on paper.
+func (e *Escpos) BitImage(img image.Image, mode BitImageMode) error {
+	m, ok := bitImageModes[mode]
+	if !ok {
+		if err := e.invalid("bit image mode", fmt.Sprint(mode)); err != nil {
+			return err
+		}
+		mode = BitImage24Double
+		m = bitImageModes[mode]
+	}
+
+	c, done := e.imageConverter()
+	defer done()
+	dotWidth, dotHeight := c.DotWidth, c.DotHeight
+	c.DotWidth, c.DotHeight = m.dotWidth, m.dotHeight
+	defer func() { c.DotWidth, c.DotHeight = dotWidth, dotHeight }()
+	width, height, data := c.Convert(img)
+	if height == 0 {
+		return nil
+	}
+	columns := raster.Columns(width, height, data, m.dots)
+
+	if err := e.command("line spacing", []byte{ESC, '3', bitImageLineSpacing}); err != nil {
+		return err
+	}
+	band := width * m.dots / 8
+	for i := 0; i < len(columns); i += band {
+		cmd := append([]byte{ESC, '*', byte(mode), byte(width), byte(width >> 8)}, columns[i:i+band]...)
+		if err := e.command("bit image", append(cmd, '\n')); err != nil {
+			return err
+		}
+	}
+
+	if e.lineSpacing < 0 {
+		return e.command("line spacing", []byte{ESC, '2'})
+	}
+	return e.command("line spacing", []byte{ESC, '3', byte(e.lineSpacing)})
+}
@@ -0,0 +1,142 @@
+package escpos
+
+// CodePage is a character code table, numbered as selected by ESC t.
+type CodePage uint8
+
+const (
+	CodePagePC437         CodePage = 0
+	CodePageKatakana      CodePage = 1
+	CodePagePC850         CodePage = 2
+	CodePagePC860         CodePage = 3
+	CodePagePC863         CodePage = 4
+	CodePagePC865         CodePage = 5
+	CodePageHiragana      CodePage = 6
+	CodePageOnePassKanji  CodePage = 7
+	CodePageOnePassKanji2 CodePage = 8
+	CodePagePC851         CodePage = 11
+	CodePagePC853         CodePage = 12
+	CodePagePC857         CodePage = 13
+	CodePagePC737         CodePage = 14
+	CodePageISO8859_7     CodePage = 15
+	CodePageWPC1252       CodePage = 16
+	CodePagePC866         CodePage = 17
+	CodePagePC852         CodePage = 18
+	CodePagePC858         CodePage = 19
+	CodePageThai42        CodePage = 20
+	CodePageThai11        CodePage = 21
+	CodePageThai13        CodePage = 22
+	CodePageThai14        CodePage = 23
+	CodePageThai16        CodePage = 24
+	CodePageThai17        CodePage = 25
+	CodePageThai18        CodePage = 26
+	CodePageTCVN3         CodePage = 30
+	CodePageTCVN3Capital  CodePage = 31
+	CodePagePC720         CodePage = 32
+	CodePageWPC775        CodePage = 33
+	CodePagePC855         CodePage = 34
+	CodePagePC861         CodePage = 35
+	CodePagePC862         CodePage = 36
+	CodePagePC864         CodePage = 37
+	CodePagePC869         CodePage = 38
+	CodePageISO8859_2     CodePage = 39
+	CodePageISO8859_15    CodePage = 40
+	CodePagePC1098        CodePage = 41
+	CodePagePC1118        CodePage = 42
+	CodePagePC1119        CodePage = 43
+	CodePagePC1125        CodePage = 44
+	CodePageWPC1250       CodePage = 45
+	CodePageWPC1251       CodePage = 46
+	CodePageWPC1253       CodePage = 47
+	CodePageWPC1254       CodePage = 48
+	CodePageWPC1255       CodePage = 49
+	CodePageWPC1256       CodePage = 50
+	CodePageWPC1257       CodePage = 51
+	CodePageWPC1258       CodePage = 52
+	CodePageKZ1048        CodePage = 53
+	CodePageDevanagari    CodePage = 66
+	CodePageBengali       CodePage = 67
+	CodePageTamil         CodePage = 68
+	CodePageTelugu        CodePage = 69
+	CodePageAssamese      CodePage = 70
+	CodePageOriya         CodePage = 71
+	CodePageKannada       CodePage = 72
+	CodePageMalayalam     CodePage = 73
+	CodePageGujarati      CodePage = 74
+	CodePagePunjabi       CodePage = 75
+	CodePageMarathi       CodePage = 82
+	CodePageUser254       CodePage = 254
+	CodePageUser255       CodePage = 255
+)
+
+// Charset is an international character set, numbered as selected by ESC R.
+// It replaces a handful of ASCII characters (#, $, @, [, \, ], ^, `, {, |,
+// }, ~) with national variants.
+type Charset uint8
+
+const (
+	CharsetUSA             Charset = 0
+	CharsetFrance          Charset = 1
+	CharsetGermany         Charset = 2
+	CharsetUK              Charset = 3
+	CharsetDenmarkI        Charset = 4
+	CharsetSweden          Charset = 5
+	CharsetItaly           Charset = 6
+	CharsetSpainI          Charset = 7
+	CharsetJapan           Charset = 8
+	CharsetNorway          Charset = 9
+	CharsetDenmarkII       Charset = 10
+	CharsetSpainII         Charset = 11
+	CharsetLatinAmerica    Charset = 12
+	CharsetKorea           Charset = 13
+	CharsetSloveniaCroatia Charset = 14
+	CharsetChina           Charset = 15
+	CharsetVietnam         Charset = 16
+	CharsetArabia          Charset = 17
+	CharsetIndiaDevanagari Charset = 66
+	CharsetIndiaBengali    Charset = 67
+	CharsetIndiaTamil      Charset = 68
+	CharsetIndiaTelugu     Charset = 69
+	CharsetIndiaAssamese   Charset = 70
+	CharsetIndiaOriya      Charset = 71
+	CharsetIndiaKannada    Charset = 72
+	CharsetIndiaMalayalam  Charset = 73
+	CharsetIndiaGujarati   Charset = 74
+	CharsetIndiaPunjabi    Charset = 75
+	CharsetIndiaMarathi    Charset = 82
+)
+
+// international character sets selected by SetLang
+var langCharsets = map[string]Charset{
+	"en": CharsetUSA,
+	"fr": CharsetFrance,
+	"de": CharsetGermany,
+	"uk": CharsetUK,
+	"da": CharsetDenmarkI,
+	"sv": CharsetSweden,
+	"it": CharsetItaly,
+	"es": CharsetSpainI,
+	"ja": CharsetJapan,
+	"no": CharsetNorway,
+	"ko": CharsetKorea,
+	"zh": CharsetChina,
+	"vi": CharsetVietnam,
+	"ar": CharsetArabia,
+}
+
+// SetCodeTable selects the character code table (ESC t) used for bytes 0x80
+// to 0xFF. Text written afterwards is transcoded to it where possible.
+func (e *Escpos) SetCodeTable(cp CodePage) error {
+	if !e.profile.SupportsCodePage(cp) {
+		return e.unsupported("code table")
+	}
+	if err := e.command("code table", []byte{ESC, 't', byte(cp)}); err != nil {
+		return err
+	}
+	e.codePage = cp
+	return nil
+}
+
+// SetInternationalCharset selects the international character set (ESC R).
+func (e *Escpos) SetInternationalCharset(c Charset) error {
+	return e.command("international charset", []byte{ESC, 'R', byte(c)})
+}
@@ -0,0 +1,163 @@
+package escpos
+
+import "fmt"
+
+// Code128 code sets
+const (
+	code128A = iota
+	code128B
+	code128C
+)
+
+// code sets in order of preference when equally narrow
+var code128Sets = [3]int{code128B, code128C, code128A}
+
+// FNC1 in GS1-128 data: the ASCII group separator marks the end of a
+// variable length element string
+const gs1Separator = 0x1d
+
+// report whether the code set encodes the character
+func code128Has(set int, c byte) bool {
+	switch set {
+	case code128A:
+		return c < 96
+	case code128B:
+		return c >= 32 && c < 128
+	}
+	return false
+}
+
+// EncodeCode128 encodes plain ASCII data for GS k Code128, choosing the code
+// sets that give the narrowest barcode: code set C packs two digits in one
+// symbol, A and B hold the control and lower case characters, and a single
+// character of the other set is shifted rather than switched to. For
+// GS1-128 the data starts with FNC1 and every ASCII group separator (0x1d)
+// in it is sent as FNC1.
+func EncodeCode128(data string, gs1 bool) (string, error) {
+	format := BarcodeFormatCode128
+	if gs1 {
+		format = BarcodeFormatGS1_128
+	}
+	for i := 0; i < len(data); i++ {
+		if data[i] > 127 {
+			return "", &BarcodeError{Format: format, Data: data, Reason: fmt.Sprintf("byte 0x%02x at position %d is not ASCII", data[i], i+1)}
+		}
+	}
+
+	fnc1 := func(i int) bool {
+		return gs1 && data[i] == gs1Separator
+	}
+
+	// cost[i][s] is the fewest symbols encoding data[:i] and ending in code
+	// set s; from[i][s] is the step that got there
+	type step struct {
+		prev, set int
+		shift     bool
+	}
+	const inf = 1 << 30
+	n := len(data)
+	cost := make([][3]int, n+1)
+	from := make([][3]step, n+1)
+	for i := range cost {
+		cost[i] = [3]int{inf, inf, inf}
+	}
+	for _, s := range code128Sets {
+		// the start code selects the first set
+		cost[0][s] = 1
+		from[0][s] = step{prev: -1, set: s}
+	}
+
+	relax := func(i, s, c int, st step) {
+		if c < cost[i][s] {
+			cost[i][s] = c
+			from[i][s] = st
+		}
+	}
+	for i := 0; i <= n; i++ {
+		// switch code sets at i
+		for _, s := range code128Sets {
+			for _, t := range code128Sets {
+				if t != s {
+					relax(i, t, cost[i][s]+1, step{prev: i, set: s})
+				}
+			}
+		}
+		if i == n {
+			break
+		}
+		for _, s := range code128Sets {
+			c := cost[i][s]
+			if c == inf {
+				continue
+			}
+			switch {
+			case fnc1(i):
+				relax(i+1, s, c+1, step{prev: i, set: s})
+			case s == code128C:
+				if i+1 < n && isDigit(data[i]) && isDigit(data[i+1]) {
+					relax(i+2, s, c+1, step{prev: i, set: s})
+				}
+			case code128Has(s, data[i]):
+				relax(i+1, s, c+1, step{prev: i, set: s})
+			default:
+				// shift a single character from the other of A and B
+				relax(i+1, s, c+2, step{prev: i, set: s, shift: true})
+			}
+		}
+	}
+
+	best := code128B
+	for _, s := range code128Sets {
+		if cost[n][s] < cost[n][best] {
+			best = s
+		}
+	}
+
+	// walk the steps back to the start, then emit them in order
+	type emit struct {
+		i, set int
+		shift  bool
+		change bool
+	}
+	var steps []emit
+	for i, s := n, best; ; {
+		st := from[i][s]
+		if st.prev < 0 {
+			steps = append(steps, emit{i: -1, set: s})
+			break
+		}
+		steps = append(steps, emit{i: st.prev, set: s, shift: st.shift, change: st.prev == i})
+		i, s = st.prev, st.set
+	}
+
+	out := make([]byte, 0, 2*n+4)
+	for k := len(steps) - 1; k >= 0; k-- {
+		st := steps[k]
+		switch {
+		case st.i < 0:
+			out = append(out, '{', "ABC"[st.set])
+			if gs1 {
+				out = append(out, '{', '1')
+			}
+		case st.change:
+			out = append(out, '{', "ABC"[st.set])
+		case fnc1(st.i):
+			out = append(out, '{', '1')
+		case st.set == code128C:
+			out = append(out, (data[st.i]-'0')*10+data[st.i+1]-'0')
+		default:
+			if st.shift {
+				out = append(out, '{', 'S')
+			}
+			if data[st.i] == '{' {
+				out = append(out, '{')
+			}
+			out = append(out, data[st.i])
+		}
+	}
+
+	if len(out) > 255 {
+		return "", &BarcodeError{Format: format, Data: data, Reason: fmt.Sprintf("%d bytes encoded, at most 255 fit", len(out))}
+	}
+	return string(out), nil
+}
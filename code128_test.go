@@ -0,0 +1,55 @@
+package escpos
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeCode128(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		gs1  bool
+		want string
+	}{
+		// code set C packs digit pairs, an odd last digit goes to B
+		{"1234", false, "{C\x0c\x22"},
+		{"0123456789012", false, "{C\x01\x17\x2d\x43\x59\x01{B2"},
+		{"AB12345678", false, "{BAB{C\x0c\x22\x38\x4e"},
+		{"12345678ab", false, "{C\x0c\x22\x38\x4e{Bab"},
+
+		// too few digits to be worth a switch
+		{"123", false, "{B123"},
+		{"x1234y", false, "{Bx1234y"},
+
+		// control characters need code set A, a single one is shifted
+		{"ABC\x01\x02\x03", false, "{AABC\x01\x02\x03"},
+		{"a\x01b", false, "{Ba{S\x01b"},
+
+		// a brace is escaped
+		{"ab{cd", false, "{Bab{{cd"},
+
+		// GS1-128 starts with FNC1 and sends the group separator as FNC1
+		{"0112345678901231", true, "{C{1\x01\x0c\x22\x38\x4e\x5a\x0c\x1f"},
+		{"10ABC\x1d3712", true, "{B{110ABC{C{1\x25\x0c"},
+		{"\x1d", false, "{A\x1d"},
+	} {
+		got, err := EncodeCode128(tc.data, tc.gs1)
+		if err != nil || got != tc.want {
+			t.Errorf("EncodeCode128(%q, %v) = %q, %v, want %q", tc.data, tc.gs1, got, err, tc.want)
+		}
+	}
+}
+
+func TestEncodeCode128Invalid(t *testing.T) {
+	for _, data := range []string{
+		"caf\xe9",
+		strings.Repeat("a", 254) + "\x01\x02",
+	} {
+		got, err := EncodeCode128(data, false)
+		var berr *BarcodeError
+		if !errors.As(err, &berr) {
+			t.Errorf("EncodeCode128(%q) = %q, %v, want a *BarcodeError", data, got, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package escpos
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// character tables text can be transcoded to, by ESC t number
+var codePageCharmaps = map[CodePage]*charmap.Charmap{
+	CodePagePC437:      charmap.CodePage437,
+	CodePagePC850:      charmap.CodePage850,
+	CodePagePC860:      charmap.CodePage860,
+	CodePagePC863:      charmap.CodePage863,
+	CodePagePC865:      charmap.CodePage865,
+	CodePageISO8859_7:  charmap.ISO8859_7,
+	CodePageWPC1252:    charmap.Windows1252,
+	CodePagePC866:      charmap.CodePage866,
+	CodePagePC852:      charmap.CodePage852,
+	CodePagePC858:      charmap.CodePage858,
+	CodePagePC855:      charmap.CodePage855,
+	CodePagePC862:      charmap.CodePage862,
+	CodePageISO8859_2:  charmap.ISO8859_2,
+	CodePageISO8859_15: charmap.ISO8859_15,
+	CodePageWPC1250:    charmap.Windows1250,
+	CodePageWPC1251:    charmap.Windows1251,
+	CodePageWPC1253:    charmap.Windows1253,
+	CodePageWPC1254:    charmap.Windows1254,
+	CodePageWPC1255:    charmap.Windows1255,
+	CodePageWPC1256:    charmap.Windows1256,
+	CodePageWPC1257:    charmap.Windows1257,
+	CodePageWPC1258:    charmap.Windows1258,
+}
+
+// encode a rune in the code page
+func encodeRune(cp CodePage, r rune) (byte, bool) {
+	cm, ok := codePageCharmaps[cp]
+	if !ok {
+		return 0, false
+	}
+	return cm.EncodeRune(r)
+}
+
+// SetReplacement sets the byte printed for runes that no code page of the
+// printer profile can represent. It defaults to '?'.
+func (e *Escpos) SetReplacement(c byte) {
+	e.replacement = c
+}
+
+// encode UTF-8 text into the printer code pages, switching tables with ESC t
+// as needed, entering Kanji mode for CJK characters when a double-byte
+// encoding is selected and selecting the user-defined character set for
+// runes defined with DefineChar. Bytes that are not valid UTF-8 are passed through
+// unchanged so that text already encoded for the printer is not altered.
+func (e *Escpos) encode(data string) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRuneInString(data[i:])
+
+		if code, ok := e.userChars[r]; ok && size > 0 && r != utf8.RuneError {
+			if e.kanji {
+				out = append(out, FS, '.')
+				e.kanji = false
+			}
+			if !e.userCharsOn {
+				out = append(out, ESC, '%', 1)
+				e.userCharsOn = true
+			}
+			out = append(out, code)
+			i += size
+			continue
+		}
+		if e.userCharsOn {
+			out = append(out, ESC, '%', 0)
+			e.userCharsOn = false
+		}
+
+		if r < utf8.RuneSelf || (r == utf8.RuneError && size == 1) {
+			out = append(out, data[i])
+			i += size
+			continue
+		}
+		i += size
+
+		if e.multibyte != MultibyteNone {
+			if b, ok := e.encodeMultibyte(r); ok {
+				if !e.kanji {
+					out = append(out, FS, '&')
+					e.kanji = true
+				}
+				out = append(out, b...)
+				continue
+			}
+		}
+		if e.kanji {
+			out = append(out, FS, '.')
+			e.kanji = false
+		}
+
+		if b, ok := encodeRune(e.codePage, r); ok {
+			out = append(out, b)
+			continue
+		}
+
+		found := false
+		for _, cp := range e.profile.CodePages {
+			if b, ok := encodeRune(cp, r); ok {
+				e.codePage = cp
+				out = append(out, ESC, 't', byte(cp), b)
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, e.replacement)
+		}
+	}
+	return out
+}
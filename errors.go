@@ -0,0 +1,72 @@
+package escpos
+
+import "fmt"
+
+// WriteError is returned when the printer destination fails to accept a
+// command. Once a write has failed the Escpos is considered broken and every
+// following command returns the same error without writing anything.
+type WriteError struct {
+	// Command is the name of the command that was being sent.
+	Command string
+
+	// Offset is the byte offset, counted from the creation of the Escpos, of
+	// the first byte that was not accepted by the destination.
+	Offset int64
+
+	// Err is the underlying error returned by the destination.
+	Err error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("escpos: %s: write failed at byte offset %d: %v", e.Command, e.Offset, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidArgumentError is returned when a command is given a value it cannot
+// send to the printer. In lenient mode it is recorded as a warning instead and
+// the value is replaced by the nearest valid one.
+type InvalidArgumentError struct {
+	// Command is the name of the command that rejected the value.
+	Command string
+
+	// Value is the rejected value as given by the caller.
+	Value string
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return fmt.Sprintf("escpos: %s: invalid value %q", e.Command, e.Value)
+}
+
+// UnsupportedError is returned when a command needs a feature that the
+// printer profile does not have.
+type UnsupportedError struct {
+	// Command is the name of the command that was refused.
+	Command string
+
+	// Model is the model name of the profile in use.
+	Model string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("escpos: %s is not supported by %s", e.Command, e.Model)
+}
+
+// BarcodeError is returned when barcode data cannot be encoded in the
+// symbology.
+type BarcodeError struct {
+	// Format is the symbology of the barcode.
+	Format BarcodeFormat
+
+	// Data is the barcode data as given by the caller.
+	Data string
+
+	// Reason tells what is wrong with the data.
+	Reason string
+}
+
+func (e *BarcodeError) Error() string {
+	return fmt.Sprintf("escpos: invalid %s barcode %q: %s", e.Format, e.Data, e.Reason)
+}
@@ -5,9 +5,9 @@ import (
 	"image"
 	"io"
 	"log"
-	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	raster "github.com/david-yappeter/escpos/raster"
 )
@@ -15,13 +15,29 @@ import (
 type BarcodeFormat int
 type QRCodeErrorCorrectionLevel uint8
 
+// 1D symbologies, numbered as the m parameter of GS k function B
 const (
-	BarcodeFormatUPC_A BarcodeFormat = iota
+	BarcodeFormatUPC_A BarcodeFormat = iota + 65
 	BarcodeFormatUPC_E
 	BarcodeFormatEAN13
 	BarcodeFormatEAN8
 	BarcodeFormatCode39
-	BarcodeFormatCode128 = iota + 67
+	BarcodeFormatITF
+	BarcodeFormatCodabar
+	BarcodeFormatCode93
+	BarcodeFormatCode128
+	BarcodeFormatGS1_128
+	BarcodeFormatGS1DataBarOmnidirectional
+	BarcodeFormatGS1DataBarTruncated
+	BarcodeFormatGS1DataBarLimited
+	BarcodeFormatGS1DataBarExpanded
+)
+
+// JAN and NW-7 are other names of EAN and Codabar
+const (
+	BarcodeFormatJAN13 = BarcodeFormatEAN13
+	BarcodeFormatJAN8  = BarcodeFormatEAN8
+	BarcodeFormatNW7   = BarcodeFormatCodabar
 )
 
 const (
@@ -38,6 +54,12 @@ const (
 	// ASCII EOT (EndOfTransmission)
 	EOT byte = 0x04
 
+	// ASCII ESC (Escape)
+	ESC byte = 0x1B
+
+	// ASCII FS (File Separator)
+	FS byte = 0x1C
+
 	// ASCII GS (Group Separator)
 	GS byte = 0x1D
 )
@@ -77,6 +99,9 @@ type Escpos struct {
 	// destination
 	dst io.ReadWriter
 
+	// printer capabilities
+	profile *Profile
+
 	// font metrics
 	width, height uint8
 
@@ -88,6 +113,61 @@ type Escpos struct {
 
 	// state toggles GS[char]
 	reverse, smooth uint8
+
+	color uint8
+
+	// bytes successfully written to dst
+	written int64
+
+	// sticky write error
+	err error
+
+	// active character code table and the byte sent for unmappable runes
+	codePage    CodePage
+	replacement byte
+
+	// double-byte encoding for CJK text and whether Kanji mode is active
+	multibyte Multibyte
+	kanji     bool
+
+	// line spacing set by SetLineSpacing, -1 for the default
+	lineSpacing int
+
+	// selected font, user-defined characters printed for runes and whether
+	// the user-defined character set is selected
+	font        uint8
+	userChars   map[rune]byte
+	userCharsOn bool
+
+	// how long reads wait for the printer
+	readTimeout time.Duration
+
+	// last process ID sent by WaitProcessed
+	processID int
+
+	// reader goroutine, unread answer bytes, the stop signal of the
+	// Automatic Status Back event delivery and whether Close was called
+	demux   *demux
+	pending []byte
+	asbStop chan struct{}
+	closed  bool
+
+	// open page mode session
+	pageMode *PageMode
+
+	// barcode settings, as changed by SetBarcodeOptions
+	barcode BarcodeOptions
+
+	// conversion of images to raster data
+	converter raster.Converter
+
+	// rows per GS v 0 band and the flow control between bands
+	imageBand    int
+	betweenBands func() error
+
+	// lenient mode clamps invalid arguments instead of failing
+	lenient  bool
+	warnings []error
 }
 
 func (e Escpos) Stored() []byte {
@@ -98,6 +178,60 @@ func (e *Escpos) ClearStored() {
 	e.stored = []byte{}
 }
 
+// Err returns the first write error encountered, if any. Once set, every
+// following command returns the same error without writing to the printer.
+func (e *Escpos) Err() error {
+	return e.err
+}
+
+// SetLenient switches between strict mode (the default), where invalid
+// arguments are returned as *InvalidArgumentError, and lenient mode, where they
+// are replaced by the nearest valid value and recorded as warnings.
+func (e *Escpos) SetLenient(lenient bool) {
+	e.lenient = lenient
+}
+
+// Warnings returns the invalid arguments recorded in lenient mode.
+func (e *Escpos) Warnings() []error {
+	return e.warnings
+}
+
+// ClearWarnings discards the recorded warnings.
+func (e *Escpos) ClearWarnings() {
+	e.warnings = nil
+}
+
+// report an invalid argument; returns nil in lenient mode so the caller can
+// continue with a corrected value
+func (e *Escpos) invalid(cmd, value string) error {
+	err := &InvalidArgumentError{Command: cmd, Value: value}
+	if !e.lenient {
+		return err
+	}
+	e.warnings = append(e.warnings, err)
+	return nil
+}
+
+// parse an integer parameter, clamping it to [min, max] in lenient mode;
+// ok is false when the value could not be used at all
+func (e *Escpos) intParam(cmd, value string, min, max int) (v int, ok bool, err error) {
+	v, err = strconv.Atoi(value)
+	if err != nil {
+		return 0, false, e.invalid(cmd, value)
+	}
+	if v < min || v > max {
+		if err = e.invalid(cmd, value); err != nil {
+			return 0, false, err
+		}
+		if v < min {
+			v = min
+		} else {
+			v = max
+		}
+	}
+	return v, true, nil
+}
+
 // reset toggles
 func (e *Escpos) reset() {
 	e.width = 1
@@ -110,105 +244,220 @@ func (e *Escpos) reset() {
 
 	e.reverse = 0
 	e.smooth = 0
+
+	e.color = 0
 }
 
-// create Escpos printer
-func New(dst io.ReadWriter) (e *Escpos) {
-	e = &Escpos{dst: dst}
+// create Escpos printer with the capabilities of profile; a nil profile
+// uses DefaultProfile. Reading from the printer may start a goroutine that
+// Close stops, so call Close before closing dst.
+func New(dst io.ReadWriter, profile *Profile) (e *Escpos) {
+	if profile == nil {
+		profile = DefaultProfile()
+	}
+	e = &Escpos{
+		dst:         dst,
+		profile:     profile,
+		replacement: '?',
+		lineSpacing: -1,
+		barcode:     defaultBarcodeOptions,
+		readTimeout: DefaultReadTimeout,
+		converter:   raster.Converter{Threshold: raster.DefaultThreshold},
+	}
 	e.reset()
 	return
 }
 
-// write raw bytes to printer
-func (e *Escpos) WriteRaw(data []byte) (n int, err error) {
-	if len(data) > 0 {
-		e.stored = append(e.stored, data...)
-		// log.Printf("Writing %d bytes\n", len(data))
-		e.dst.Write(data)
-	} else {
+// SetDither sets the algorithm and luminance threshold (0-1) used to reduce
+// images to black and white.
+func (e *Escpos) SetDither(dither raster.Dither, threshold float64) {
+	e.converter.Dither = dither
+	e.converter.Threshold = threshold
+}
+
+// SetConverter sets how Image and RasterImage convert images. A zero MaxWidth
+// is replaced by the paper width of the profile.
+func (e *Escpos) SetConverter(c raster.Converter) {
+	e.converter = c
+}
+
+// the image converter, its zero MaxWidth replaced by the paper width until
+// done is called. Converted data is reused by the next conversion.
+func (e *Escpos) imageConverter() (c *raster.Converter, done func()) {
+	c = &e.converter
+	if c.MaxWidth != 0 {
+		return c, func() {}
+	}
+	c.MaxWidth = e.profile.PaperWidth
+	return c, func() { c.MaxWidth = 0 }
+}
+
+// convert an image to raster data with the converter settings
+func (e *Escpos) convert(img image.Image) (width, height int, data []byte) {
+	c, done := e.imageConverter()
+	defer done()
+	return c.Convert(img)
+}
+
+// Profile returns the printer profile in use.
+func (e *Escpos) Profile() *Profile {
+	return e.profile
+}
+
+// report a command the printer profile does not support
+func (e *Escpos) unsupported(cmd string) error {
+	return &UnsupportedError{Command: cmd, Model: e.profile.Model}
+}
+
+// write a named command to the printer, recording any failure
+func (e *Escpos) write(cmd string, data []byte) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	if len(data) == 0 {
 		log.Printf("Wrote NO bytes\n")
+		return 0, nil
+	}
+
+	n, err = e.dst.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+	e.stored = append(e.stored, data[:n]...)
+	if err != nil {
+		e.err = &WriteError{Command: cmd, Offset: e.written + int64(n), Err: err}
+		e.written += int64(n)
+		return n, e.err
 	}
+	e.written += int64(n)
 
-	return 0, nil
+	return n, nil
 }
 
-// read raw bytes from printer
+// send a named command to the printer
+func (e *Escpos) command(cmd string, data []byte) error {
+	_, err := e.write(cmd, data)
+	return err
+}
+
+// write raw bytes to printer
+func (e *Escpos) WriteRaw(data []byte) (n int, err error) {
+	return e.write("raw", data)
+}
+
+// read raw bytes from printer, waiting at most the read timeout
 func (e *Escpos) ReadRaw(data []byte) (n int, err error) {
-	return e.dst.Read(data)
+	return e.read(data)
 }
 
-// write a string to the printer
+// write a UTF-8 string to the printer, transcoded to its code pages
 func (e *Escpos) Write(data string) (int, error) {
-	return e.WriteRaw([]byte(data))
+	return e.write("text", e.encode(data))
 }
 
-// write a string to the printer
+// write a string followed by a linefeed to the printer
 func (e *Escpos) WriteLn(data string) (int, error) {
-	sz, err := e.WriteRaw([]byte(data))
-	e.Linefeed()
-	return sz, err
+	sz, err := e.Write(data)
+	if err != nil {
+		return sz, err
+	}
+	return sz, e.Linefeed()
 }
 
-func (e *Escpos) PrintRasterImage(img image.Image, incrementation int, xL, xH, yL, yH, dxL, dxH, dyL, dyH byte) {
-	var ESC byte = 0x1b
-	printWidth, printHeight, data := raster.PrintRasterImageProcess(img)
+// print an image as ESC * 33 bit images, each band placed in its own page
+// mode print area moved down by incrementation
+//
+// Deprecated: use BitImage, which needs no page mode.
+func (e *Escpos) PrintRasterImage(img image.Image, incrementation int, xL, xH, yL, yH, dxL, dxH, dyL, dyH byte) error {
+	if !e.profile.PageMode {
+		return e.unsupported("page mode")
+	}
+	printWidth, printHeight, data := raster.PrintRasterImageProcessDither(img, e.converter.Dither, e.converter.Threshold)
 
 	var yPos byte = yL
 	var yPosH byte = yH
 	for i := 0; i < printHeight/8/3; i++ {
-		e.WriteRaw([]byte{ESC, 87, xL, xH, yPos, yPosH, dxL, dxH, dyL, dyH})
-		e.WriteRaw(append([]byte{ESC, 42, 33, byte(printWidth), byte(printWidth / 256)}, data[i*printWidth*3:((i+1)*printWidth*3)]...))
+		if err := e.command("print area", []byte{ESC, 87, xL, xH, yPos, yPosH, dxL, dxH, dyL, dyH}); err != nil {
+			return err
+		}
+		if err := e.command("bit image", append([]byte{ESC, 42, 33, byte(printWidth), byte(printWidth / 256)}, data[i*printWidth*3:((i+1)*printWidth*3)]...)); err != nil {
+			return err
+		}
 
 		if int(yPos)+incrementation > 255 {
 			yPosH += byte(int(yPos) + incrementation)
 		}
 		yPos = byte(int(yPos) + incrementation)
 	}
+	return nil
 }
 
 // init/reset printer settings
-func (e *Escpos) Init() {
+func (e *Escpos) Init() error {
 	e.reset()
-	e.Write("\x1B@")
+	e.codePage = CodePagePC437
+	e.kanji = false
+	e.font = 0
+	e.lineSpacing = -1
+	e.barcode = defaultBarcodeOptions
+	e.userChars = nil
+	e.userCharsOn = false
+	if e.pageMode != nil {
+		// ESC @ returns to standard mode
+		e.pageMode.closed = true
+		e.pageMode = nil
+	}
+	return e.command("init", []byte("\x1B@"))
 }
 
 // end output
-func (e *Escpos) End() {
-	e.Write("\xFA")
+func (e *Escpos) End() error {
+	return e.command("end", []byte("\xFA"))
 }
 
 // send cut
-func (e *Escpos) Cut() {
-	e.Write("\x1DVA0")
+func (e *Escpos) Cut() error {
+	if !e.profile.Cutter {
+		return e.unsupported("cut")
+	}
+	return e.command("cut", []byte("\x1DVA0"))
 }
 
 // send cut minus one point (partial cut)
-func (e *Escpos) CutPartial() {
-	e.WriteRaw([]byte{GS, 0x56, 1})
+func (e *Escpos) CutPartial() error {
+	if !e.profile.PartialCutter {
+		// fall back to a full cut
+		return e.Cut()
+	}
+	return e.command("partial cut", []byte{GS, 0x56, 1})
 }
 
 // send cash
-func (e *Escpos) Cash() {
-	e.Write("\x1B\x70\x00\x0A\xFF")
+func (e *Escpos) Cash() error {
+	if !e.profile.Drawer {
+		return e.unsupported("cash")
+	}
+	return e.command("cash", []byte("\x1B\x70\x00\x0A\xFF"))
 }
 
 // send linefeed
-func (e *Escpos) Linefeed() {
-	e.Write("\n")
+func (e *Escpos) Linefeed() error {
+	return e.command("linefeed", []byte("\n"))
 }
 
 // send N formfeeds
-func (e *Escpos) FormfeedN(n int) {
-	e.Write(fmt.Sprintf("\x1Bd%c", n))
+func (e *Escpos) FormfeedN(n int) error {
+	return e.command("formfeed", []byte(fmt.Sprintf("\x1Bd%c", n)))
 }
 
 // send formfeed
-func (e *Escpos) Formfeed() {
-	e.FormfeedN(1)
+func (e *Escpos) Formfeed() error {
+	return e.FormfeedN(1)
 }
 
 // set font
-func (e *Escpos) SetFont(font string) {
+func (e *Escpos) SetFont(font string) error {
 	f := 0
 
 	switch font {
@@ -219,112 +468,161 @@ func (e *Escpos) SetFont(font string) {
 	case "C":
 		f = 2
 	default:
-		log.Fatalf("Invalid font: '%s', defaulting to 'A'", font)
+		if err := e.invalid("font", font); err != nil {
+			return err
+		}
 		f = 0
 	}
 
-	e.Write(fmt.Sprintf("\x1BM%c", f))
+	if name := string(rune('A' + f)); !e.profile.HasFont(name) {
+		return e.unsupported("font " + name)
+	}
+
+	if err := e.command("font", []byte(fmt.Sprintf("\x1BM%c", f))); err != nil {
+		return err
+	}
+	e.font = uint8(f)
+	return nil
+}
+
+func (e *Escpos) SendFontSize() error {
+	return e.command("font size", []byte(fmt.Sprintf("\x1D!%c", ((e.width-1)<<4)|(e.height-1))))
 }
 
-func (e *Escpos) SendFontSize() {
-	e.Write(fmt.Sprintf("\x1D!%c", ((e.width-1)<<4)|(e.height-1)))
+// clamp a font multiplier to the valid 1-max range
+func clampFontSize(v, max uint8) uint8 {
+	if v < 1 {
+		return 1
+	}
+	if v > max {
+		return max
+	}
+	return v
 }
 
 // set font size
-func (e *Escpos) SetFontSize(width, height uint8) {
-	if width > 0 && height > 0 && width <= 8 && height <= 8 {
-		e.width = width
-		e.height = height
-		e.SendFontSize()
-	} else {
-		log.Fatalf("Invalid font size passed: %d x %d", width, height)
+func (e *Escpos) SetFontSize(width, height uint8) error {
+	max := e.profile.MaxFontSize
+	if width == 0 || height == 0 || width > max || height > max {
+		if err := e.invalid("font size", fmt.Sprintf("%dx%d", width, height)); err != nil {
+			return err
+		}
+		width, height = clampFontSize(width, max), clampFontSize(height, max)
 	}
+	e.width = width
+	e.height = height
+	return e.SendFontSize()
 }
 
 // send underline
-func (e *Escpos) SendUnderline() {
-	e.Write(fmt.Sprintf("\x1B-%c", e.underline))
+func (e *Escpos) SendUnderline() error {
+	return e.command("underline", []byte(fmt.Sprintf("\x1B-%c", e.underline)))
 }
 
 // send emphasize / doublestrike
-func (e *Escpos) SendEmphasize() {
-	e.Write(fmt.Sprintf("\x1BG%c", e.emphasize))
+func (e *Escpos) SendEmphasize() error {
+	return e.command("emphasize", []byte(fmt.Sprintf("\x1BG%c", e.emphasize)))
 }
 
 // send upsidedown
-func (e *Escpos) SendUpsidedown() {
-	e.Write(fmt.Sprintf("\x1B{%c", e.upsidedown))
+func (e *Escpos) SendUpsidedown() error {
+	return e.command("upsidedown", []byte(fmt.Sprintf("\x1B{%c", e.upsidedown)))
 }
 
-// send rotate
-func (e *Escpos) SendRotate() {
-	e.Write(fmt.Sprintf("\x1BR%c", e.rotate))
+// send rotate (90 degrees clockwise) -- ESC V
+func (e *Escpos) SendRotate() error {
+	return e.command("rotate", []byte(fmt.Sprintf("\x1BV%c", e.rotate)))
 }
 
 // send reverse
-func (e *Escpos) SendReverse() {
-	e.Write(fmt.Sprintf("\x1DB%c", e.reverse))
+func (e *Escpos) SendReverse() error {
+	return e.command("reverse", []byte(fmt.Sprintf("\x1DB%c", e.reverse)))
 }
 
 // send smooth
-func (e *Escpos) SendSmooth() {
-	e.Write(fmt.Sprintf("\x1Db%c", e.smooth))
+func (e *Escpos) SendSmooth() error {
+	return e.command("smooth", []byte(fmt.Sprintf("\x1Db%c", e.smooth)))
+}
+
+// send print colour -- ESC r, 0 black, 1 red on two-colour paper
+func (e *Escpos) SendColor() error {
+	if !e.profile.TwoColor {
+		return e.unsupported("color")
+	}
+	return e.command("color", []byte{ESC, 'r', e.color})
 }
 
 // send move x
-func (e *Escpos) SendMoveX(x uint16) {
-	e.Write(string([]byte{0x1b, 0x24, byte(x % 256), byte(x / 256)}))
+func (e *Escpos) SendMoveX(x uint16) error {
+	return e.command("move x", []byte{0x1b, 0x24, byte(x % 256), byte(x / 256)})
 }
 
 // send move y
-func (e *Escpos) SendMoveY(y uint16) {
-	e.Write(string([]byte{0x1d, 0x24, byte(y % 256), byte(y / 256)}))
+func (e *Escpos) SendMoveY(y uint16) error {
+	return e.command("move y", []byte{0x1d, 0x24, byte(y % 256), byte(y / 256)})
 }
 
 // set underline
-func (e *Escpos) SetUnderline(v uint8) {
+func (e *Escpos) SetUnderline(v uint8) error {
 	e.underline = v
-	e.SendUnderline()
+	return e.SendUnderline()
 }
 
 // set emphasize
-func (e *Escpos) SetEmphasize(u uint8) {
+func (e *Escpos) SetEmphasize(u uint8) error {
 	e.emphasize = u
-	e.SendEmphasize()
+	return e.SendEmphasize()
 }
 
 // set upsidedown
-func (e *Escpos) SetUpsidedown(v uint8) {
+func (e *Escpos) SetUpsidedown(v uint8) error {
 	e.upsidedown = v
-	e.SendUpsidedown()
+	return e.SendUpsidedown()
 }
 
 // set rotate
-func (e *Escpos) SetRotate(v uint8) {
+func (e *Escpos) SetRotate(v uint8) error {
 	e.rotate = v
-	e.SendRotate()
+	return e.SendRotate()
 }
 
 // set reverse
-func (e *Escpos) SetReverse(v uint8) {
+func (e *Escpos) SetReverse(v uint8) error {
 	e.reverse = v
-	e.SendReverse()
+	return e.SendReverse()
 }
 
 // set smooth
-func (e *Escpos) SetSmooth(v uint8) {
+func (e *Escpos) SetSmooth(v uint8) error {
 	e.smooth = v
-	e.SendSmooth()
+	return e.SendSmooth()
+}
+
+// set print colour, 0 black or 1 red; in lenient mode any other value is
+// clamped to red
+func (e *Escpos) SetColor(v uint8) error {
+	if v > 1 {
+		if err := e.invalid("color", fmt.Sprint(v)); err != nil {
+			return err
+		}
+		v = 1
+	}
+	e.color = v
+	return e.SendColor()
 }
 
 // pulse (open the drawer)
-func (e *Escpos) Pulse() {
+func (e *Escpos) Pulse() error {
+	if !e.profile.Drawer {
+		return e.unsupported("pulse")
+	}
+
 	// with t=2 -- meaning 2*2msec
-	e.Write("\x1Bp\x02")
+	return e.command("pulse", []byte("\x1Bp\x02"))
 }
 
 // set alignment
-func (e *Escpos) SetAlign(align string) {
+func (e *Escpos) SetAlign(align string) error {
 	a := 0
 	switch align {
 	case "left":
@@ -334,287 +632,278 @@ func (e *Escpos) SetAlign(align string) {
 	case "right":
 		a = 2
 	default:
-		log.Fatalf("Invalid alignment: %s", align)
-	}
-	e.Write(fmt.Sprintf("\x1Ba%c", a))
-}
-
-// set language -- ESC R
-func (e *Escpos) SetLang(lang string) {
-	l := 0
-
-	switch lang {
-	case "en":
-		l = 0
-	case "fr":
-		l = 1
-	case "de":
-		l = 2
-	case "uk":
-		l = 3
-	case "da":
-		l = 4
-	case "sv":
-		l = 5
-	case "it":
-		l = 6
-	case "es":
-		l = 7
-	case "ja":
-		l = 8
-	case "no":
-		l = 9
-	default:
-		log.Fatalf("Invalid language: %s", lang)
+		if err := e.invalid("align", align); err != nil {
+			return err
+		}
 	}
-	e.Write(fmt.Sprintf("\x1BR%c", l))
+	return e.command("align", []byte(fmt.Sprintf("\x1Ba%c", a)))
+}
+
+// set language -- selects the matching international character set (ESC R)
+func (e *Escpos) SetLang(lang string) error {
+	c, ok := langCharsets[lang]
+	if !ok {
+		if err := e.invalid("lang", lang); err != nil {
+			return err
+		}
+		c = CharsetUSA
+	}
+	return e.SetInternationalCharset(c)
 }
 
 // do a block of text
-func (e *Escpos) Text(params map[string]string, data string) {
+func (e *Escpos) Text(params map[string]string, data string) error {
 
 	// send alignment to printer
 	if align, ok := params["align"]; ok {
-		e.SetAlign(align)
+		if err := e.SetAlign(align); err != nil {
+			return err
+		}
 	}
 
 	// set lang
 	if lang, ok := params["lang"]; ok {
-		e.SetLang(lang)
+		if err := e.SetLang(lang); err != nil {
+			return err
+		}
 	}
 
 	// set smooth
 	if smooth, ok := params["smooth"]; ok && (smooth == "true" || smooth == "1") {
-		e.SetSmooth(1)
+		if err := e.SetSmooth(1); err != nil {
+			return err
+		}
 	}
 
 	// set emphasize
 	if em, ok := params["em"]; ok && (em == "true" || em == "1") {
-		e.SetEmphasize(1)
+		if err := e.SetEmphasize(1); err != nil {
+			return err
+		}
 	}
 
 	// set underline
 	if ul, ok := params["ul"]; ok && (ul == "true" || ul == "1") {
-		e.SetUnderline(1)
+		if err := e.SetUnderline(1); err != nil {
+			return err
+		}
 	}
 
 	// set reverse
 	if reverse, ok := params["reverse"]; ok && (reverse == "true" || reverse == "1") {
-		e.SetReverse(1)
+		if err := e.SetReverse(1); err != nil {
+			return err
+		}
 	}
 
 	// set rotate
 	if rotate, ok := params["rotate"]; ok && (rotate == "true" || rotate == "1") {
-		e.SetRotate(1)
+		if err := e.SetRotate(1); err != nil {
+			return err
+		}
+	}
+
+	// set colour
+	if color, ok := params["color"]; ok {
+		v := uint8(0)
+		switch color {
+		case "black":
+		case "red":
+			v = 1
+		default:
+			if err := e.invalid("color", color); err != nil {
+				return err
+			}
+		}
+		if err := e.SetColor(v); err != nil {
+			return err
+		}
 	}
 
 	// set font
 	if font, ok := params["font"]; ok {
-		e.SetFont(strings.ToUpper(font[5:6]))
+		if len(font) != 6 || !strings.HasPrefix(font, "font_") {
+			if err := e.invalid("font", font); err != nil {
+				return err
+			}
+			font = "font_a"
+		}
+		if err := e.SetFont(strings.ToUpper(font[5:6])); err != nil {
+			return err
+		}
 	}
 
 	// do dw (double font width)
 	if dw, ok := params["dw"]; ok && (dw == "true" || dw == "1") {
-		e.SetFontSize(2, e.height)
+		if err := e.SetFontSize(2, e.height); err != nil {
+			return err
+		}
 	}
 
 	// do dh (double font height)
 	if dh, ok := params["dh"]; ok && (dh == "true" || dh == "1") {
-		e.SetFontSize(e.width, 2)
+		if err := e.SetFontSize(e.width, 2); err != nil {
+			return err
+		}
 	}
 
 	// do font width
 	if width, ok := params["width"]; ok {
-		if i, err := strconv.Atoi(width); err == nil {
-			e.SetFontSize(uint8(i), e.height)
-		} else {
-			log.Fatalf("Invalid font width: %s", width)
+		if i, ok, err := e.intParam("font width", width, 1, int(e.profile.MaxFontSize)); err != nil {
+			return err
+		} else if ok {
+			if err := e.SetFontSize(uint8(i), e.height); err != nil {
+				return err
+			}
 		}
 	}
 
 	// do font height
 	if height, ok := params["height"]; ok {
-		if i, err := strconv.Atoi(height); err == nil {
-			e.SetFontSize(e.width, uint8(i))
-		} else {
-			log.Fatalf("Invalid font height: %s", height)
+		if i, ok, err := e.intParam("font height", height, 1, int(e.profile.MaxFontSize)); err != nil {
+			return err
+		} else if ok {
+			if err := e.SetFontSize(e.width, uint8(i)); err != nil {
+				return err
+			}
 		}
 	}
 
-	// do y positioning
+	// do x positioning
 	if x, ok := params["x"]; ok {
-		if i, err := strconv.Atoi(x); err == nil {
-			e.SendMoveX(uint16(i))
-		} else {
-			log.Fatalf("Invalid x param %s", x)
+		if i, ok, err := e.intParam("move x", x, 0, 0xffff); err != nil {
+			return err
+		} else if ok {
+			if err := e.SendMoveX(uint16(i)); err != nil {
+				return err
+			}
 		}
 	}
 
 	// do y positioning
 	if y, ok := params["y"]; ok {
-		if i, err := strconv.Atoi(y); err == nil {
-			e.SendMoveY(uint16(i))
-		} else {
-			log.Fatalf("Invalid y param %s", y)
+		if i, ok, err := e.intParam("move y", y, 0, 0xffff); err != nil {
+			return err
+		} else if ok {
+			if err := e.SendMoveY(uint16(i)); err != nil {
+				return err
+			}
 		}
 	}
 
 	// do text replace, then write data
 	data = textReplace(data)
 	if len(data) > 0 {
-		e.Write(data)
+		if _, err := e.Write(data); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // feed the printer
-func (e *Escpos) Feed(params map[string]string) {
+func (e *Escpos) Feed(params map[string]string) error {
 	// handle lines (form feed X lines)
 	if l, ok := params["line"]; ok {
-		if i, err := strconv.Atoi(l); err == nil {
-			e.FormfeedN(i)
-		} else {
-			log.Fatalf("Invalid line number %s", l)
+		if i, ok, err := e.intParam("feed lines", l, 0, 0xff); err != nil {
+			return err
+		} else if ok {
+			if err := e.FormfeedN(i); err != nil {
+				return err
+			}
 		}
 	}
 
 	// handle units (dots)
 	if u, ok := params["unit"]; ok {
-		if i, err := strconv.Atoi(u); err == nil {
-			e.SendMoveY(uint16(i))
-		} else {
-			log.Fatalf("Invalid unit number %s", u)
+		if i, ok, err := e.intParam("feed units", u, 0, 0xffff); err != nil {
+			return err
+		} else if ok {
+			if err := e.SendMoveY(uint16(i)); err != nil {
+				return err
+			}
 		}
 	}
 
 	// send linefeed
-	e.Linefeed()
+	if err := e.Linefeed(); err != nil {
+		return err
+	}
 
 	// reset variables
 	e.reset()
 
 	// reset printer
-	e.SendEmphasize()
-	e.SendRotate()
-	e.SendSmooth()
-	e.SendReverse()
-	e.SendUnderline()
-	e.SendUpsidedown()
-	e.SendFontSize()
-	e.SendUnderline()
+	for _, send := range []func() error{
+		e.SendEmphasize,
+		e.SendRotate,
+		e.SendSmooth,
+		e.SendReverse,
+		e.SendUnderline,
+		e.SendUpsidedown,
+		e.SendFontSize,
+		e.SendUnderline,
+	} {
+		if err := send(); err != nil {
+			return err
+		}
+	}
+	if e.profile.TwoColor {
+		return e.SendColor()
+	}
+	return nil
 }
 
 // feed and cut based on parameters
-func (e *Escpos) FeedAndCut(params map[string]string) {
+func (e *Escpos) FeedAndCut(params map[string]string) error {
 	if t, ok := params["type"]; ok && t == "feed" {
-		e.Formfeed()
-	}
-
-	e.Cut()
-}
-
-// Barcode sends a barcode to the printer.
-func (e *Escpos) Barcode(barcode string, format BarcodeFormat) {
-	var code byte
-	switch format {
-	case BarcodeFormatUPC_A:
-		code = 0x00
-	case BarcodeFormatUPC_E:
-		code = 0x01
-	case BarcodeFormatEAN13:
-		code = 0x02
-	case BarcodeFormatEAN8:
-		code = 0x03
-	case BarcodeFormatCode39:
-		code = 0x04
-	case BarcodeFormatCode128:
-		code = 0x49
+		if err := e.Formfeed(); err != nil {
+			return err
+		}
 	}
 
-	// reset settings
-	e.reset()
-
-	// set align
-	e.SetAlign("center")
-
-	// write barcode
-	if format > 69 {
-		e.Write(string(append([]byte{GS, 'k', code, byte(len(barcode))}, []byte(barcode)...)))
-	} else if format < 69 {
-		e.Write(string(append(append([]byte{GS, 'k', code}, []byte(barcode)...), 0x00)))
-	}
-	// e.Write(fmt.Sprintf("%v", barcode))
+	return e.Cut()
 }
 
-func (e *Escpos) QRCode(code string, model bool, size uint8, correctionLevel QRCodeErrorCorrectionLevel) (int, error) {
-	if len(code) > 7089 {
-		return 0, fmt.Errorf("the code is too long, it's length should be smaller than 7090")
-	}
-	if size < 1 {
-		size = 1
-	}
-	if size > 16 {
-		size = 16
-	}
-	var m byte = 49
-	var err error
-	// set the qr code model
-	if model {
-		m = 50
-	}
-	_, err = e.WriteRaw([]byte{GS, '(', 'k', 4, 0, 49, 65, m, 0})
-	if err != nil {
-		return 0, err
-	}
-
-	// set the qr code size
-	_, err = e.WriteRaw([]byte{GS, '(', 'k', 3, 0, 49, 67, size})
-	if err != nil {
-		return 0, err
-	}
-
-	// set the qr code error correction level
-	if correctionLevel < 48 {
-		correctionLevel = 48
-	}
-	if correctionLevel > 51 {
-		correctionLevel = 51
-	}
-	_, err = e.WriteRaw([]byte{GS, '(', 'k', 3, 0, 49, 69, size})
-	if err != nil {
-		return 0, err
+// print an image with GS v 0 raster bit images, in bands of the height set by
+// SetImageBand, converted as set by SetConverter and SetDither
+func (e *Escpos) Image(img image.Image) error {
+	width, height, data := e.convert(img)
+	if !e.profile.RasterImage {
+		if !e.profile.Graphics {
+			return e.unsupported("image")
+		}
+		// fall back to GS 8 L graphics
+		return e.Raster(width, height, width>>3, data)
 	}
 
-	// store the data in the buffer
-	// we now write stuff to the printer, so lets save it for returning
-
-	// pL and pH define the size of the data. Data ranges from 1 to (pL + pH*256)-3
-	// 3 < pL + pH*256 < 7093
-	var codeLength = len(code) + 3
-	var pL, pH byte
-	pH = byte(int(math.Floor(float64(codeLength) / 256)))
-	pL = byte(codeLength - 256*int(pH))
-
-	written, err := e.WriteRaw(append([]byte{GS, '(', 'k', pL, pH, 49, 80, 48}, []byte(code)...))
-	if err != nil {
-		return written, err
+	bytesWidth := width >> 3
+	band := e.imageBand
+	if band <= 0 {
+		band = DefaultImageBandHeight
 	}
-
-	// finally print the buffer
-	_, err = e.WriteRaw([]byte{GS, '(', 'k', 3, 0, 49, 81, 48})
-	if err != nil {
-		return written, err
+	for l := 0; l < height; l += band {
+		if l > 0 {
+			if err := e.nextBand(); err != nil {
+				return err
+			}
+		}
+		n := band
+		if n > height-l {
+			n = height - l
+		}
+		if err := e.command("image", []byte{GS, 'v', 48, 0, byte(bytesWidth), byte(bytesWidth >> 8), byte(n), byte(n >> 8)}); err != nil {
+			return err
+		}
+		if err := e.command("image", data[l*bytesWidth:(l+n)*bytesWidth]); err != nil {
+			return err
+		}
 	}
-
-	return written, nil
-}
-
-func (e *Escpos) Image(img image.Image) {
-	xL, xH, yL, yH, data := raster.PrintImage(img)
-	e.WriteRaw(append([]byte{GS, 'v', 48, 0, xL, xH, yL, yH}, data...))
+	return nil
 }
 
 // write a "node" to the printer
-func (e *Escpos) WriteNode(name string, params map[string]string, data string) {
+func (e *Escpos) WriteNode(name string, params map[string]string, data string) error {
 	cstr := ""
 	if data != "" {
 		str := data[:]
@@ -627,23 +916,29 @@ func (e *Escpos) WriteNode(name string, params map[string]string, data string) {
 
 	switch name {
 	case "text":
-		e.Text(params, data)
+		return e.Text(params, data)
 	case "feed":
-		e.Feed(params)
+		return e.Feed(params)
 	case "cut":
-		e.FeedAndCut(params)
+		return e.FeedAndCut(params)
 	case "pulse":
-		e.Pulse()
+		return e.Pulse()
 	}
+	return nil
 }
 
 // ReadStatus Read the status n from the printer
 func (e *Escpos) ReadStatus(n byte) (byte, error) {
-	e.WriteRaw([]byte{DLE, EOT, n})
+	if err := e.query("status", []byte{DLE, EOT, n}, answerByte); err != nil {
+		return 0, err
+	}
 	data := make([]byte, 1)
-	_, err := e.ReadRaw(data)
+	read, err := e.ReadRaw(data)
 	if err != nil {
 		return 0, err
 	}
+	if read == 0 {
+		return 0, io.ErrNoProgress
+	}
 	return data[0], nil
 }
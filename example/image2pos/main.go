@@ -10,8 +10,8 @@ import (
 	_ "image/jpeg"
 	_ "image/png"
 
-	"github.com/knq/escpos"
-	"github.com/knq/escpos/raster"
+	"github.com/david-yappeter/escpos"
+	"github.com/david-yappeter/escpos/raster"
 )
 
 var (
@@ -49,7 +49,7 @@ func main() {
 	defer f.Close()
 	log.Print(*lpDev, " open.")
 
-	ep := escpos.New(f)
+	ep := escpos.New(f, nil)
 
 	ep.Init()
 
@@ -60,10 +60,14 @@ func main() {
 		Threshold: *threshold,
 	}
 
-	rasterConv.Print(img, ep)
+	if err := rasterConv.Print(img, ep); err != nil {
+		log.Fatal(err)
+	}
 
 	if *doCut {
 		ep.Cut()
 	}
-	ep.End()
+	if err := ep.End(); err != nil {
+		log.Fatal(err)
+	}
 }
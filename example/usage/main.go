@@ -22,19 +22,19 @@ func main() {
 	reade := bufio.NewReader(f)
 
 	w := bufio.NewReadWriter(reade, write)
-	p := escpos.New(w)
+	p := escpos.New(w, nil)
 
 	p.Init()
 	p.SetAlign("center")
 
-	p.Barcode("1234A", escpos.BarcodeFormatCode128)
+	p.Barcode("1234A", escpos.BarcodeFormatCode128, nil)
 
 	p.Linefeed()
 	p.Linefeed()
 
 	w.Flush()
 
-	p.QRCode("ABCDE", true, 10, escpos.QRCodeErrorCorrectionLevelM)
+	p.QRCode("ABCDE", &escpos.QROptions{ModuleSize: 10, ErrorCorrection: escpos.QRCodeErrorCorrectionLevelM})
 
 	p.Linefeed()
 	p.Linefeed()
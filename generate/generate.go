@@ -3,7 +3,6 @@ package generate
 import (
 	"fmt"
 	"image"
-	"math"
 
 	"github.com/david-yappeter/escpos"
 	"github.com/david-yappeter/escpos/raster"
@@ -91,7 +90,7 @@ func SetUpsidedown(v uint8) []byte {
 }
 
 func SetRotate(v uint8) []byte {
-	return []byte(fmt.Sprintf("\x1BR%c", v))
+	return []byte(fmt.Sprintf("\x1BV%c", v))
 }
 
 func SetReverse(v uint8) []byte {
@@ -102,6 +101,10 @@ func SetSmooth(v uint8) []byte {
 	return []byte(fmt.Sprintf("\x1Db%c", v))
 }
 
+func SetColor(v uint8) []byte {
+	return []byte{esc, 'r', v}
+}
+
 func SetMoveX(x uint16) []byte {
 	return []byte{0x1b, 0x24, byte(x % 256), byte(x / 256)}
 }
@@ -125,79 +128,89 @@ func SetAlign(align string) []byte {
 	return []byte(fmt.Sprintf("\x1Ba%c", a))
 }
 
-func Barcode(barcode string, format escpos.BarcodeFormat) []byte {
-	var code byte
-	switch format {
-	case escpos.BarcodeFormatUPC_A:
-		code = 0x00
-	case escpos.BarcodeFormatUPC_E:
-		code = 0x01
-	case escpos.BarcodeFormatEAN13:
-		code = 0x02
-	case escpos.BarcodeFormatEAN8:
-		code = 0x03
-	case escpos.BarcodeFormatCode39:
-		code = 0x04
-	case escpos.BarcodeFormatCode128:
-		code = 0x49
-	}
+func SetCodeTable(cp escpos.CodePage) []byte {
+	return []byte{esc, 't', byte(cp)}
+}
 
-	// write barcode
-	if format > 69 {
-		return append([]byte{gs, 'k', code, byte(len(barcode))}, []byte(barcode)...)
-	} else if format < 69 {
-		return append(append([]byte{gs, 'k', code}, []byte(barcode)...), 0x00)
+func SetInternationalCharset(c escpos.Charset) []byte {
+	return []byte{esc, 'R', byte(c)}
+}
+
+// Barcode returns the commands printing a barcode, its data checked with
+// escpos.ValidateBarcode. Fields of opts, if not nil, are sent before the
+// barcode and stay in effect after it; out of range heights and widths are
+// clamped, and unknown HRI positions and fonts are left out.
+func Barcode(barcode string, format escpos.BarcodeFormat, opts *escpos.BarcodeOptions) ([]byte, error) {
+	barcode, err := escpos.ValidateBarcode(format, barcode)
+	if err != nil {
+		return nil, err
 	}
 
-	return []byte{}
+	var data []byte
+	if opts != nil {
+		if opts.Height != 0 {
+			data = append(data, gs, 'h', byte(clamp(opts.Height, 1, 255)))
+		}
+		if opts.Width != 0 {
+			data = append(data, gs, 'w', byte(clamp(opts.Width, 2, 6)))
+		}
+		if opts.HRI >= escpos.HRINotPrinted && opts.HRI <= escpos.HRIBoth {
+			data = append(data, gs, 'H', byte(opts.HRI-1))
+		}
+		switch opts.HRIFont {
+		case "A", "B", "C":
+			data = append(data, gs, 'f', opts.HRIFont[0]-'A')
+		}
+	}
+	return append(append(data, gs, 'k', byte(format), byte(len(barcode))), barcode...), nil
 }
 
-func QRCode(code string, model bool, size uint8, correctionLevel escpos.QRCodeErrorCorrectionLevel) ([]byte, error) {
-	datas := []byte{}
-	if len(code) > 7089 {
-		return nil, fmt.Errorf("the code is too long, it's length should be smaller than 7090")
+// QRCode returns the commands printing a QR code. Zero fields of opts, or a
+// nil opts, take the printer defaults; out of range values are clamped.
+func QRCode(code string, opts *escpos.QROptions) ([]byte, error) {
+	if len(code) == 0 || len(code) > 7089 {
+		return nil, &escpos.InvalidArgumentError{Command: "qr code", Value: fmt.Sprintf("%d bytes of data", len(code))}
 	}
-	if size < 1 {
-		size = 1
+	var o escpos.QROptions
+	if opts != nil {
+		o = *opts
 	}
-	if size > 16 {
-		size = 16
+
+	model := o.Model
+	if model < escpos.QRModel1 || model > escpos.QRModelMicro {
+		model = escpos.QRModel2
 	}
-	var m byte = 49
-	// set the qr code model
-	if model {
-		m = 50
+	size := 3
+	if o.ModuleSize != 0 {
+		size = clamp(o.ModuleSize, 1, 16)
 	}
-	datas = append(datas, []byte{gs, '(', 'k', 4, 0, 49, 65, m, 0}...)
-
-	// set the qr code size
-	datas = append(datas, []byte{gs, '(', 'k', 3, 0, 49, 67, size}...)
-
-	// set the qr code error correction level
-	if correctionLevel < 48 {
-		correctionLevel = 48
+	level := o.ErrorCorrection
+	maxLevel := escpos.QRCodeErrorCorrectionLevelH
+	if model == escpos.QRModelMicro {
+		maxLevel = escpos.QRCodeErrorCorrectionLevelQ
 	}
-	if correctionLevel > 51 {
-		correctionLevel = 51
+	if level < escpos.QRCodeErrorCorrectionLevelL {
+		level = escpos.QRCodeErrorCorrectionLevelL
+	}
+	if level > maxLevel {
+		level = maxLevel
 	}
-	datas = append(datas, []byte{gs, '(', 'k', 3, 0, 49, 69, size}...)
-
-	// store the data in the buffer
-	// we now write stuff to the printer, so lets save it for returning
-
-	// pL and pH define the size of the data. Data ranges from 1 to (pL + pH*256)-3
-	// 3 < pL + pH*256 < 7093
-	var codeLength = len(code) + 3
-	var pL, pH byte
-	pH = byte(int(math.Floor(float64(codeLength) / 256)))
-	pL = byte(codeLength - 256*int(pH))
-
-	datas = append(datas, append([]byte{gs, '(', 'k', pL, pH, 49, 80, 48}, []byte(code)...)...)
-
-	// finally print the buffer
-	datas = append(datas, []byte{gs, '(', 'k', 3, 0, 49, 81, 48}...)
 
-	return datas, nil
+	var data []byte
+	if o.Align != "" {
+		data = append(data, SetAlign(o.Align)...)
+	}
+	data = append(data,
+		gs, '(', 'k', 4, 0, 49, 65, byte(model), 0,
+		gs, '(', 'k', 3, 0, 49, 67, byte(size),
+		gs, '(', 'k', 3, 0, 49, 69, byte(level),
+	)
+
+	// store the data in the symbol storage area, then print it
+	p := len(code) + 3
+	data = append(data, gs, '(', 'k', byte(p), byte(p>>8), 49, 80, 48)
+	data = append(data, code...)
+	return append(data, gs, '(', 'k', 3, 0, 49, 81, 48), nil
 }
 
 func SetMarginLeft(marginLeft int) []byte {
@@ -244,7 +257,7 @@ func SetPrintDirection(direction uint8) []byte {
 	default:
 		direction = 0
 	}
-	return []byte{gs, 84, direction}
+	return []byte{esc, 84, direction}
 }
 
 func PrintPageModeBufferData() []byte {
@@ -254,3 +267,30 @@ func PrintPageModeBufferData() []byte {
 func SetAbsolutePosition(v int) []byte {
 	return []byte{esc, 36, byte(v % 256), byte(v / 256)}
 }
+
+func SetAbsoluteVerticalPosition(v int) []byte {
+	return []byte{gs, 36, byte(v % 256), byte(v / 256)}
+}
+
+func SetRelativePosition(v int) []byte {
+	return []byte{esc, 92, byte(v), byte(v >> 8)}
+}
+
+func SetRelativeVerticalPosition(v int) []byte {
+	return []byte{gs, 92, byte(v), byte(v >> 8)}
+}
+
+func CancelPageModeData() []byte {
+	return []byte{0x18}
+}
+
+// limit v to the range min-max
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
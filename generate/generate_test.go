@@ -0,0 +1,97 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/david-yappeter/escpos"
+)
+
+// the commands printing a QR code, after the alignment
+func qrCommands(model escpos.QRModel, size int, level escpos.QRCodeErrorCorrectionLevel, data string) []byte {
+	p := len(data) + 3
+	cmd := []byte{
+		gs, '(', 'k', 4, 0, 49, 65, byte(model), 0,
+		gs, '(', 'k', 3, 0, 49, 67, byte(size),
+		gs, '(', 'k', 3, 0, 49, 69, byte(level),
+		gs, '(', 'k', byte(p), byte(p >> 8), 49, 80, 48,
+	}
+	cmd = append(cmd, data...)
+	return append(cmd, gs, '(', 'k', 3, 0, 49, 81, 48)
+}
+
+func TestQRCode(t *testing.T) {
+	const (
+		L = escpos.QRCodeErrorCorrectionLevelL
+		M = escpos.QRCodeErrorCorrectionLevelM
+		Q = escpos.QRCodeErrorCorrectionLevelQ
+		H = escpos.QRCodeErrorCorrectionLevelH
+	)
+	for _, tc := range []struct {
+		name  string
+		opts  *escpos.QROptions
+		align []byte
+		want  []byte
+	}{
+		{"defaults", nil, nil, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+		{"zero options", &escpos.QROptions{}, nil, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+		{"model 1", &escpos.QROptions{Model: escpos.QRModel1}, nil, qrCommands(escpos.QRModel1, 3, L, "ABC")},
+		{"micro", &escpos.QROptions{Model: escpos.QRModelMicro}, nil, qrCommands(escpos.QRModelMicro, 3, L, "ABC")},
+		{"size 1", &escpos.QROptions{ModuleSize: 1}, nil, qrCommands(escpos.QRModel2, 1, L, "ABC")},
+		{"size 16", &escpos.QROptions{ModuleSize: 16}, nil, qrCommands(escpos.QRModel2, 16, L, "ABC")},
+		{"level M", &escpos.QROptions{ErrorCorrection: M}, nil, qrCommands(escpos.QRModel2, 3, M, "ABC")},
+		{"level Q", &escpos.QROptions{ErrorCorrection: Q}, nil, qrCommands(escpos.QRModel2, 3, Q, "ABC")},
+		{"level H", &escpos.QROptions{ErrorCorrection: H}, nil, qrCommands(escpos.QRModel2, 3, H, "ABC")},
+		{"left", &escpos.QROptions{Align: "left"}, []byte{esc, 'a', 0}, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+		{"center", &escpos.QROptions{Align: "center"}, []byte{esc, 'a', 1}, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+		{"right", &escpos.QROptions{Align: "right"}, []byte{esc, 'a', 2}, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+		{"all", &escpos.QROptions{Model: escpos.QRModel1, ModuleSize: 6, ErrorCorrection: H, Align: "center"},
+			[]byte{esc, 'a', 1}, qrCommands(escpos.QRModel1, 6, H, "ABC")},
+
+		// out of range values are clamped
+		{"bad model", &escpos.QROptions{Model: 9}, nil, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+		{"size 17", &escpos.QROptions{ModuleSize: 17}, nil, qrCommands(escpos.QRModel2, 16, L, "ABC")},
+		{"size -1", &escpos.QROptions{ModuleSize: -1}, nil, qrCommands(escpos.QRModel2, 1, L, "ABC")},
+		{"level above H", &escpos.QROptions{ErrorCorrection: 60}, nil, qrCommands(escpos.QRModel2, 3, H, "ABC")},
+		{"level below L", &escpos.QROptions{ErrorCorrection: 1}, nil, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+		{"micro level H", &escpos.QROptions{Model: escpos.QRModelMicro, ErrorCorrection: H}, nil,
+			qrCommands(escpos.QRModelMicro, 3, Q, "ABC")},
+		{"bad align", &escpos.QROptions{Align: "middle"}, []byte{esc, 'a', 0}, qrCommands(escpos.QRModel2, 3, L, "ABC")},
+	} {
+		got, err := QRCode("ABC", tc.opts)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		want := append(tc.align, tc.want...)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: got\n% x\nwant\n% x", tc.name, got, want)
+		}
+	}
+}
+
+func TestQRCodeLongData(t *testing.T) {
+	data := strings.Repeat("1", 300)
+	got, err := QRCode(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := qrCommands(escpos.QRModel2, 3, escpos.QRCodeErrorCorrectionLevelL, data); !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got[:30], want[:30])
+	}
+}
+
+func TestQRCodeData(t *testing.T) {
+	for _, data := range []string{"", strings.Repeat("1", 7090)} {
+		got, err := QRCode(data, nil)
+		var invalid *escpos.InvalidArgumentError
+		if !errors.As(err, &invalid) {
+			t.Errorf("%d bytes of data: error %v, want an InvalidArgumentError", len(data), err)
+		}
+		if got != nil {
+			t.Errorf("%d bytes of data: got % x", len(data), got)
+		}
+	}
+}
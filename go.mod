@@ -6,4 +6,5 @@ require (
 	github.com/gorilla/mux v1.8.0
 	github.com/knq/escpos v0.0.0-20201012084129-81d0344e35fa
 	github.com/moovweb/gokogiri v0.0.0-20180713195410-a1a828153468
+	golang.org/x/text v0.13.0
 )
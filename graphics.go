@@ -0,0 +1,206 @@
+package escpos
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+)
+
+// ASCII ACK (Acknowledge)
+const ACK byte = 0x06
+
+// GraphicsMemory is where stored graphics are kept.
+type GraphicsMemory uint8
+
+const (
+	// NV graphics survive power cycles; the memory wears with every write,
+	// so define logos once at setup rather than for every receipt
+	GraphicsNV GraphicsMemory = iota
+	// download graphics are lost when the printer is reset or turned off
+	GraphicsDownload
+)
+
+// GS ( L / GS 8 L function codes of a graphics memory
+type graphicsFuncs struct {
+	name                                   string
+	keys, deleteAll, delete, define, print byte
+}
+
+var graphicsMemories = map[GraphicsMemory]graphicsFuncs{
+	GraphicsNV:       {"NV graphics", 64, 65, 66, 67, 69},
+	GraphicsDownload: {"download graphics", 80, 81, 82, 83, 85},
+}
+
+// look up the function codes of a graphics memory
+func (e *Escpos) graphicsMemory(m GraphicsMemory) (graphicsFuncs, error) {
+	if !e.profile.Graphics {
+		return graphicsFuncs{}, e.unsupported("stored graphics")
+	}
+	f, ok := graphicsMemories[m]
+	if !ok {
+		return f, &InvalidArgumentError{Command: "graphics memory", Value: fmt.Sprint(m)}
+	}
+	return f, nil
+}
+
+// check a key code: two printable ASCII characters
+func (e *Escpos) graphicsKey(cmd, key string) error {
+	if len(key) != 2 || key[0] < 32 || key[0] > 126 || key[1] < 32 || key[1] > 126 {
+		return &InvalidArgumentError{Command: cmd, Value: key}
+	}
+	return nil
+}
+
+// a GS ( L function with the parameters
+func graphicsData(fn byte, params ...byte) []byte {
+	p := len(params) + 2
+	return append([]byte{GS, '(', 'L', byte(p), byte(p >> 8), 48, fn}, params...)
+}
+
+// send a GS ( L function with the parameters
+func (e *Escpos) graphicsFunc(cmd string, fn byte, params ...byte) error {
+	return e.command(cmd, graphicsData(fn, params...))
+}
+
+// DefineGraphics stores an image in graphics memory under a key of two
+// printable ASCII characters, replacing any graphics with the same key. The
+// image is converted as set by SetConverter and SetDither.
+func (e *Escpos) DefineGraphics(m GraphicsMemory, key string, img image.Image) error {
+	f, err := e.graphicsMemory(m)
+	if err != nil {
+		return err
+	}
+	if err := e.graphicsKey(f.name, key); err != nil {
+		return err
+	}
+
+	c, done := e.imageConverter()
+	defer done()
+	width, height, data := c.Convert(img)
+	if height == 0 {
+		return &InvalidArgumentError{Command: f.name, Value: "empty image"}
+	}
+
+	// GS 8 L p1 p2 p3 p4 m fn a kc1 kc2 b xL xH yL yH c d1...dk
+	p := 11 + len(data)
+	if err := e.command(f.name, []byte{
+		GS, '8', 'L', byte(p), byte(p >> 8), byte(p >> 16), byte(p >> 24),
+		48, f.define, 48, key[0], key[1], 1,
+		byte(width), byte(width >> 8), byte(height), byte(height >> 8),
+		0x31,
+	}); err != nil {
+		return err
+	}
+	return e.command(f.name, data)
+}
+
+// PrintGraphics prints the graphics stored under key, scaled by 1 or 2 in
+// each direction.
+func (e *Escpos) PrintGraphics(m GraphicsMemory, key string, scaleX, scaleY int) error {
+	f, err := e.graphicsMemory(m)
+	if err != nil {
+		return err
+	}
+	if err := e.graphicsKey(f.name, key); err != nil {
+		return err
+	}
+	if scaleX < 1 || scaleX > 2 || scaleY < 1 || scaleY > 2 {
+		if err := e.invalid(f.name+" scale", fmt.Sprintf("%dx%d", scaleX, scaleY)); err != nil {
+			return err
+		}
+		scaleX, scaleY = clampInt(scaleX, 1, 2), clampInt(scaleY, 1, 2)
+	}
+	return e.graphicsFunc(f.name, f.print, key[0], key[1], byte(scaleX), byte(scaleY))
+}
+
+// DeleteGraphics deletes the graphics stored under key.
+func (e *Escpos) DeleteGraphics(m GraphicsMemory, key string) error {
+	f, err := e.graphicsMemory(m)
+	if err != nil {
+		return err
+	}
+	if err := e.graphicsKey(f.name, key); err != nil {
+		return err
+	}
+	return e.graphicsFunc(f.name, f.delete, key[0], key[1])
+}
+
+// DeleteAllGraphics deletes all graphics in the memory.
+func (e *Escpos) DeleteAllGraphics(m GraphicsMemory) error {
+	f, err := e.graphicsMemory(m)
+	if err != nil {
+		return err
+	}
+	return e.graphicsFunc(f.name, f.deleteAll, 'C', 'L', 'R')
+}
+
+// GraphicsKeys lists the keys of the graphics stored in the memory.
+func (e *Escpos) GraphicsKeys(m GraphicsMemory) ([]string, error) {
+	f, err := e.graphicsMemory(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.query(f.name, graphicsData(f.keys, 'K', 'C'), answerBlock); err != nil {
+		return nil, err
+	}
+
+	// the list comes in blocks: 0x37 0x72 status keys..., where status
+	// 0x41 means another block follows once acknowledged
+	var keys []string
+	for {
+		block, err := e.readBlock()
+		if err != nil {
+			return keys, err
+		}
+		if len(block) < 3 || block[0] != 0x37 || len(block[3:])%2 != 0 {
+			return keys, fmt.Errorf("escpos: unexpected answer %q to %s key list", block, f.name)
+		}
+		for i := 3; i < len(block); i += 2 {
+			keys = append(keys, string(block[i:i+2]))
+		}
+		if block[2] != 0x41 {
+			return keys, nil
+		}
+		if err := e.command(f.name, []byte{ACK}); err != nil {
+			return keys, err
+		}
+	}
+}
+
+// query a graphics memory capacity in bytes
+func (e *Escpos) graphicsCapacity(cmd string, fn byte) (int, error) {
+	if !e.profile.Graphics {
+		return 0, e.unsupported(cmd)
+	}
+	if err := e.query(cmd, graphicsData(fn), answerBlock); err != nil {
+		return 0, err
+	}
+	block, err := e.readBlock()
+	if err != nil {
+		return 0, err
+	}
+	if len(block) < 3 || block[0] != 0x37 {
+		return 0, fmt.Errorf("escpos: unexpected answer %q to %s query", block, cmd)
+	}
+	n, err := strconv.Atoi(string(block[2:]))
+	if err != nil {
+		return 0, fmt.Errorf("escpos: unexpected answer %q to %s query", block, cmd)
+	}
+	return n, nil
+}
+
+// NVGraphicsCapacity queries the size of the NV graphics memory in bytes.
+func (e *Escpos) NVGraphicsCapacity() (int, error) {
+	return e.graphicsCapacity("NV graphics capacity", 48)
+}
+
+// GraphicsRemaining queries the free space left in the memory in bytes.
+func (e *Escpos) GraphicsRemaining(m GraphicsMemory) (int, error) {
+	if _, err := e.graphicsMemory(m); err != nil {
+		return 0, err
+	}
+	if m == GraphicsDownload {
+		return e.graphicsCapacity("download graphics capacity", 52)
+	}
+	return e.graphicsCapacity("NV graphics capacity", 51)
+}
@@ -0,0 +1,194 @@
+package escpos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// how long PrinterInfo waits for the answers to GS I 65-69, which many
+// printers ignore
+const infoTimeout = 300 * time.Millisecond
+
+// PrinterInfo is the printer identification returned by GS I.
+type PrinterInfo struct {
+	// ModelID, TypeID and VersionID are the one-byte IDs of GS I 1-3.
+	ModelID   byte
+	TypeID    byte
+	VersionID byte
+
+	// Multibyte and Cutter are decoded from TypeID: the printer supports
+	// double-byte characters and has an auto cutter.
+	Multibyte bool
+	Cutter    bool
+
+	// Firmware, Maker, Model, SerialNumber and Font are the answers to
+	// GS I 65-69. Printers that do not implement them leave them empty.
+	Firmware     string
+	Maker        string
+	Model        string
+	SerialNumber string
+	Font         string
+}
+
+// query a one-byte printer ID
+func (e *Escpos) queryID(n byte) (byte, error) {
+	if err := e.query("printer id", []byte{GS, 'I', n}, answerByte); err != nil {
+		return 0, err
+	}
+	data := make([]byte, 1)
+	read, err := e.ReadRaw(data)
+	if err != nil {
+		return 0, err
+	}
+	if read == 0 {
+		return 0, io.ErrNoProgress
+	}
+	return data[0], nil
+}
+
+// read a block answer from the printer, up to and without the closing NUL
+func (e *Escpos) readBlock() ([]byte, error) {
+	var block []byte
+	data := make([]byte, 1)
+	for {
+		read, err := e.ReadRaw(data)
+		if err != nil {
+			return nil, err
+		}
+		if read == 0 {
+			return nil, io.ErrNoProgress
+		}
+		if data[0] == 0 {
+			return block, nil
+		}
+		block = append(block, data[0])
+	}
+}
+
+// query a printer information string, sent back as "_" data NUL
+func (e *Escpos) queryInfo(n byte) (string, error) {
+	if err := e.query("printer id", []byte{GS, 'I', n}, answerBlock); err != nil {
+		return "", err
+	}
+
+	block, err := e.readBlock()
+	if err != nil {
+		return "", err
+	}
+	if len(block) == 0 || block[0] != 0x5f {
+		return "", fmt.Errorf("escpos: unexpected answer %q to GS I %d", block, n)
+	}
+	return string(block[1:]), nil
+}
+
+// PrinterInfo queries the printer IDs and information strings (GS I). The
+// information strings are skipped when the printer does not answer them
+// within 300ms, or the read timeout if shorter.
+func (e *Escpos) PrinterInfo() (PrinterInfo, error) {
+	var info PrinterInfo
+	var err error
+
+	if info.ModelID, err = e.queryID(1); err != nil {
+		return info, err
+	}
+	if info.TypeID, err = e.queryID(2); err != nil {
+		return info, err
+	}
+	if info.VersionID, err = e.queryID(3); err != nil {
+		return info, err
+	}
+	info.Multibyte = info.TypeID&0x01 != 0
+	info.Cutter = info.TypeID&0x02 != 0
+
+	// a printer that does not answer the first string query will not answer
+	// the others either, so do not wait long for it
+	timeout := e.readTimeout
+	if timeout <= 0 || timeout > infoTimeout {
+		e.readTimeout = infoTimeout
+	}
+	defer func() { e.readTimeout = timeout }()
+
+	for _, q := range []struct {
+		n   byte
+		dst *string
+	}{
+		{65, &info.Firmware},
+		{66, &info.Maker},
+		{67, &info.Model},
+		{68, &info.SerialNumber},
+		{69, &info.Font},
+	} {
+		s, err := e.queryInfo(q.n)
+		if errors.Is(err, ErrTimeout) {
+			break
+		}
+		if err != nil {
+			return info, err
+		}
+		*q.dst = s
+	}
+
+	return info, nil
+}
+
+// MatchProfile returns the built-in profile for a model name as reported by
+// the printer. Names are compared case insensitively and without spaces, and
+// the longest built-in model the name starts with wins, so that "TM-T88V-i"
+// matches the TM-T88V profile.
+func MatchProfile(model string) (*Profile, bool) {
+	name := strings.ToUpper(strings.Replace(model, " ", "", -1))
+	if name == "" {
+		return nil, false
+	}
+
+	best := ""
+	for key := range profiles {
+		if strings.HasPrefix(name, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+	return LookupProfile(best)
+}
+
+// Detect queries the printer on rw for its identification and returns an
+// Escpos using the matching built-in profile. The profile is matched on the
+// model name of GS I 67 only, as model IDs are not unique across makers, so
+// printers that do not answer it, or have no built-in profile, get
+// DefaultProfile, named after the reported maker and model. The auto cutter
+// and double-byte support are taken from the printer's type ID. As with New,
+// call Close on the returned Escpos when done.
+func Detect(rw io.ReadWriter) (*Escpos, PrinterInfo, error) {
+	e := New(rw, nil)
+	info, err := e.PrinterInfo()
+	if err != nil {
+		e.Close()
+		return nil, info, err
+	}
+
+	profile, ok := MatchProfile(info.Model)
+	if !ok {
+		profile = DefaultProfile()
+		if info.Maker != "" {
+			profile.Vendor = info.Maker
+		}
+		if info.Model != "" {
+			profile.Model = info.Model
+		}
+	}
+	if !info.Cutter {
+		profile.Cutter = false
+		profile.PartialCutter = false
+	}
+	if !info.Multibyte {
+		profile.Multibyte = nil
+	}
+
+	e.profile = profile
+	return e, info, nil
+}
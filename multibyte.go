@@ -0,0 +1,90 @@
+package escpos
+
+import (
+	"strconv"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// Multibyte is a double-byte character encoding printed in Kanji mode.
+type Multibyte int
+
+const (
+	MultibyteNone Multibyte = iota
+	MultibyteShiftJIS
+	MultibyteGB18030
+	MultibyteBig5
+	MultibyteEUCKR
+)
+
+// text encodings of the double-byte modes
+var multibyteEncodings = map[Multibyte]encoding.Encoding{
+	MultibyteShiftJIS: japanese.ShiftJIS,
+	MultibyteGB18030:  simplifiedchinese.GB18030,
+	MultibyteBig5:     traditionalchinese.Big5,
+	MultibyteEUCKR:    korean.EUCKR,
+}
+
+// SetMultibyte selects the double-byte encoding used for CJK text. Text
+// written afterwards switches the printer into Kanji mode (FS &) for the
+// characters the encoding has, and back out of it (FS .) for characters that
+// are printed from the code pages. MultibyteNone disables the mode.
+func (e *Escpos) SetMultibyte(m Multibyte) error {
+	if m == MultibyteNone {
+		e.multibyte = m
+		return e.kanjiMode(false)
+	}
+	if _, ok := multibyteEncodings[m]; !ok {
+		if err := e.invalid("multibyte", strconv.Itoa(int(m))); err != nil {
+			return err
+		}
+		return e.SetMultibyte(MultibyteNone)
+	}
+	if !e.profile.SupportsMultibyte(m) {
+		return e.unsupported("multibyte")
+	}
+
+	// Japanese models select the Kanji code system, FS C n
+	if m == MultibyteShiftJIS {
+		if err := e.command("kanji code system", []byte{FS, 'C', 1}); err != nil {
+			return err
+		}
+	}
+	e.multibyte = m
+	return nil
+}
+
+// enter or leave Kanji mode
+func (e *Escpos) kanjiMode(on bool) error {
+	if e.kanji == on {
+		return nil
+	}
+	cmd := []byte{FS, '.'}
+	if on {
+		cmd = []byte{FS, '&'}
+	}
+	if err := e.command("kanji mode", cmd); err != nil {
+		return err
+	}
+	e.kanji = on
+	return nil
+}
+
+// encode a rune with the active double-byte encoding. Only double-byte
+// codes are printable in Kanji mode: GB18030 encodes every rune, most of
+// them in four bytes, and those are left to the code pages.
+func (e *Escpos) encodeMultibyte(r rune) ([]byte, bool) {
+	enc, ok := multibyteEncodings[e.multibyte]
+	if !ok {
+		return nil, false
+	}
+	b, err := enc.NewEncoder().Bytes([]byte(string(r)))
+	if err != nil || len(b) != 2 || b[0] < 0x81 {
+		return nil, false
+	}
+	return b, true
+}
@@ -0,0 +1,240 @@
+package escpos
+
+import (
+	"fmt"
+	"math"
+)
+
+// ASCII FF (Form Feed) and CAN (Cancel)
+const (
+	FF  byte = 0x0C
+	CAN byte = 0x18
+)
+
+// PrintDirection is the page mode print direction, named after the direction
+// characters advance in, as selected by ESC T.
+type PrintDirection uint8
+
+const (
+	// left to right, starting at the upper left corner
+	DirectionLeftToRight PrintDirection = iota
+	// bottom to top, starting at the lower left corner
+	DirectionBottomToTop
+	// right to left, starting at the lower right corner
+	DirectionRightToLeft
+	// top to bottom, starting at the upper right corner
+	DirectionTopToBottom
+)
+
+// PageMode is a page mode session. Data written to the Escpos while the
+// session is open is laid out in the print area and only printed by Print or
+// PrintAndExit. Positions are in dots, relative to the print area and in the
+// orientation of the print direction.
+type PageMode struct {
+	e *Escpos
+
+	// print area in dots; zero width means the printer default
+	x, y, width, height int
+
+	direction PrintDirection
+
+	// current position set by MoveTo/MoveBy
+	posX, posY int
+
+	closed bool
+}
+
+// EnterPageMode switches the printer to page mode (ESC L) and returns the
+// session. Only one session can be open at a time.
+func (e *Escpos) EnterPageMode() (*PageMode, error) {
+	if !e.profile.PageMode {
+		return nil, e.unsupported("page mode")
+	}
+	if e.pageMode != nil {
+		return nil, fmt.Errorf("escpos: page mode is already active")
+	}
+	if err := e.command("page mode", []byte{ESC, 'L'}); err != nil {
+		return nil, err
+	}
+	e.pageMode = &PageMode{e: e}
+	return e.pageMode, nil
+}
+
+// check the session can still send commands
+func (p *PageMode) check() error {
+	if p.closed {
+		return fmt.Errorf("escpos: page mode session is closed")
+	}
+	return nil
+}
+
+// horizontal and vertical extent of the area in the print direction
+func (p *PageMode) extent() (int, int) {
+	if p.direction == DirectionBottomToTop || p.direction == DirectionTopToBottom {
+		return p.height, p.width
+	}
+	return p.width, p.height
+}
+
+// SetArea sets the print area (ESC W) in dots. The area must fit the paper
+// width of the profile.
+func (p *PageMode) SetArea(x, y, width, height int) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	paper := p.e.profile.PaperWidth
+	if x < 0 || y < 0 || width < 1 || height < 1 || x+width > paper || y+height > 0xffff {
+		if err := p.e.invalid("print area", fmt.Sprintf("%d,%d %dx%d", x, y, width, height)); err != nil {
+			return err
+		}
+		x, y = clampInt(x, 0, paper-1), clampInt(y, 0, 0xfffe)
+		width = clampInt(width, 1, paper-x)
+		height = clampInt(height, 1, 0xffff-y)
+	}
+
+	if err := p.e.command("print area", []byte{
+		ESC, 'W',
+		byte(x), byte(x >> 8), byte(y), byte(y >> 8),
+		byte(width), byte(width >> 8), byte(height), byte(height >> 8),
+	}); err != nil {
+		return err
+	}
+	p.x, p.y, p.width, p.height = x, y, width, height
+	p.posX, p.posY = 0, 0
+	return nil
+}
+
+// SetAreaMM sets the print area in millimetres, converted to dots with the
+// resolution of the profile.
+func (p *PageMode) SetAreaMM(x, y, width, height float64) error {
+	return p.SetArea(p.e.mmToDots(x), p.e.mmToDots(y), p.e.mmToDots(width), p.e.mmToDots(height))
+}
+
+// convert millimetres to dots
+func (e *Escpos) mmToDots(mm float64) int {
+	return int(math.Round(mm * float64(e.profile.DPI) / 25.4))
+}
+
+// SetDirection sets the print direction and starting corner (ESC T).
+func (p *PageMode) SetDirection(d PrintDirection) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	if d > DirectionTopToBottom {
+		if err := p.e.invalid("print direction", fmt.Sprint(d)); err != nil {
+			return err
+		}
+		d = DirectionLeftToRight
+	}
+	if err := p.e.command("print direction", []byte{ESC, 'T', byte(d)}); err != nil {
+		return err
+	}
+	p.direction = d
+	p.posX, p.posY = 0, 0
+	return nil
+}
+
+// validate a position against the print area
+func (p *PageMode) position(x, y int) (int, int, error) {
+	w, h := p.extent()
+	if w == 0 {
+		w, h = 0x10000, 0x10000
+	}
+	if x < 0 || y < 0 || x >= w || y >= h {
+		if err := p.e.invalid("page position", fmt.Sprintf("%d,%d", x, y)); err != nil {
+			return 0, 0, err
+		}
+		x, y = clampInt(x, 0, w-1), clampInt(y, 0, h-1)
+	}
+	return x, y, nil
+}
+
+// MoveTo sets the absolute print position (ESC $, GS $).
+func (p *PageMode) MoveTo(x, y int) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	x, y, err := p.position(x, y)
+	if err != nil {
+		return err
+	}
+	if err := p.e.command("page position", []byte{
+		ESC, '$', byte(x), byte(x >> 8),
+		GS, '$', byte(y), byte(y >> 8),
+	}); err != nil {
+		return err
+	}
+	p.posX, p.posY = x, y
+	return nil
+}
+
+// MoveBy moves the print position relative to the last position set with
+// MoveTo or MoveBy (ESC \, GS \).
+func (p *PageMode) MoveBy(dx, dy int) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	x, y, err := p.position(p.posX+dx, p.posY+dy)
+	if err != nil {
+		return err
+	}
+	dx, dy = x-p.posX, y-p.posY
+	if err := p.e.command("page position", []byte{
+		ESC, '\\', byte(dx), byte(dx >> 8),
+		GS, '\\', byte(dy), byte(dy >> 8),
+	}); err != nil {
+		return err
+	}
+	p.posX, p.posY = x, y
+	return nil
+}
+
+// Print prints the page and stays in page mode (ESC FF).
+func (p *PageMode) Print() error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	return p.e.command("print page", []byte{ESC, FF})
+}
+
+// Cancel deletes the data in the print area (CAN).
+func (p *PageMode) Cancel() error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	return p.e.command("cancel page", []byte{CAN})
+}
+
+// PrintAndExit prints the page and returns to standard mode (FF), closing
+// the session.
+func (p *PageMode) PrintAndExit() error {
+	return p.exit([]byte{FF})
+}
+
+// Exit discards the page and returns to standard mode (ESC S), closing the
+// session.
+func (p *PageMode) Exit() error {
+	return p.exit([]byte{ESC, 'S'})
+}
+
+// leave page mode
+func (p *PageMode) exit(cmd []byte) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	p.closed = true
+	p.e.pageMode = nil
+	return p.e.command("standard mode", cmd)
+}
+
+// clamp an int to [min, max]
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,426 @@
+package escpos
+
+import (
+	"sort"
+	"strings"
+)
+
+// Symbology2D is a two-dimensional code symbology.
+type Symbology2D int
+
+const (
+	Symbology2DQRCode Symbology2D = iota
+	Symbology2DPDF417
+	Symbology2DMaxiCode
+	Symbology2DDataMatrix
+	Symbology2DAztec
+)
+
+// Profile describes the capabilities of a printer model. Commands consult the
+// profile of their Escpos so that unsupported features fail before anything
+// is sent, or fall back to an equivalent command where one exists.
+type Profile struct {
+	// Vendor and Model identify the printer.
+	Vendor string
+	Model  string
+
+	// PaperWidth is the printable width in dots.
+	PaperWidth int
+
+	// DPI is the print resolution in dots per inch.
+	DPI int
+
+	// CharsPerLine maps each font ("A", "B", "C") the printer has to the
+	// number of characters per line at normal size.
+	CharsPerLine map[string]int
+
+	// MaxFontSize is the largest width/height multiplier accepted by GS !.
+	MaxFontSize uint8
+
+	// Barcodes lists the supported 1D symbologies.
+	Barcodes []BarcodeFormat
+
+	// Symbologies2D lists the supported 2D symbologies.
+	Symbologies2D []Symbology2D
+
+	// CodePages lists the character code tables available through ESC t.
+	CodePages []CodePage
+
+	// Multibyte lists the double-byte encodings available in Kanji mode.
+	Multibyte []Multibyte
+
+	// Cutter and PartialCutter report an auto cutter and whether it can
+	// leave one point uncut.
+	Cutter        bool
+	PartialCutter bool
+
+	// Drawer reports a cash drawer kick-out connector.
+	Drawer bool
+
+	// PageMode reports support for page mode (ESC L).
+	PageMode bool
+
+	// RasterImage reports support for GS v 0 raster bit images.
+	RasterImage bool
+
+	// Graphics reports support for GS ( L / GS 8 L graphics.
+	Graphics bool
+
+	// MaxRasterBandHeight is the largest number of rows sent in a single
+	// GS 8 L command.
+	MaxRasterBandHeight int
+
+	// GrayLevels is the number of shades of multi-tone graphics, 4 or 16,
+	// or 0 for black and white only.
+	GrayLevels int
+
+	// TwoColor reports support for two-colour paper or ribbon: red text
+	// (ESC r) and, with Graphics, two-colour images.
+	TwoColor bool
+}
+
+// HasFont reports whether the printer has the font ("A", "B" or "C").
+func (p *Profile) HasFont(font string) bool {
+	_, ok := p.CharsPerLine[font]
+	return ok
+}
+
+// SupportsBarcode reports whether the printer can print the 1D symbology.
+func (p *Profile) SupportsBarcode(format BarcodeFormat) bool {
+	for _, f := range p.Barcodes {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Supports2D reports whether the printer can print the 2D symbology.
+func (p *Profile) Supports2D(s Symbology2D) bool {
+	for _, v := range p.Symbologies2D {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsMultibyte reports whether the printer has the double-byte
+// encoding.
+func (p *Profile) SupportsMultibyte(m Multibyte) bool {
+	for _, v := range p.Multibyte {
+		if v == m {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsCodePage reports whether the printer has the code table.
+func (p *Profile) SupportsCodePage(cp CodePage) bool {
+	for _, v := range p.CodePages {
+		if v == cp {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	commonBarcodes = []BarcodeFormat{
+		BarcodeFormatUPC_A,
+		BarcodeFormatUPC_E,
+		BarcodeFormatEAN13,
+		BarcodeFormatEAN8,
+		BarcodeFormatCode39,
+		BarcodeFormatITF,
+		BarcodeFormatCodabar,
+		BarcodeFormatCode93,
+		BarcodeFormatCode128,
+	}
+
+	// recent Epson models add GS1-128 and GS1 DataBar
+	gs1Barcodes = append(commonBarcodes[:len(commonBarcodes):len(commonBarcodes)],
+		BarcodeFormatGS1_128,
+		BarcodeFormatGS1DataBarOmnidirectional,
+		BarcodeFormatGS1DataBarTruncated,
+		BarcodeFormatGS1DataBarLimited,
+		BarcodeFormatGS1DataBarExpanded,
+	)
+
+	epsonCodePages = []CodePage{
+		CodePagePC437,
+		CodePageKatakana,
+		CodePagePC850,
+		CodePagePC860,
+		CodePagePC863,
+		CodePagePC865,
+		CodePageWPC1252,
+		CodePagePC866,
+		CodePagePC852,
+		CodePagePC858,
+		CodePageISO8859_7,
+		CodePagePC855,
+		CodePagePC862,
+		CodePageISO8859_2,
+		CodePageISO8859_15,
+		CodePageWPC1250,
+		CodePageWPC1251,
+		CodePageWPC1253,
+		CodePageWPC1254,
+		CodePageWPC1255,
+		CodePageWPC1256,
+		CodePageWPC1257,
+		CodePageWPC1258,
+	}
+
+	westernCodePages = []CodePage{
+		CodePagePC437,
+		CodePagePC850,
+		CodePagePC860,
+		CodePagePC863,
+		CodePagePC865,
+		CodePageWPC1252,
+		CodePagePC866,
+		CodePagePC852,
+		CodePagePC858,
+	}
+)
+
+// DefaultProfile returns a permissive profile for a generic 80mm ESC/POS
+// printer. It is used when New is given no profile.
+func DefaultProfile() *Profile {
+	p := Profile{
+		Vendor:              "Generic",
+		Model:               "ESC/POS",
+		PaperWidth:          576,
+		DPI:                 203,
+		CharsPerLine:        map[string]int{"A": 48, "B": 64, "C": 72},
+		MaxFontSize:         8,
+		Barcodes:            gs1Barcodes,
+		Symbologies2D:       []Symbology2D{Symbology2DQRCode},
+		CodePages:           epsonCodePages,
+		Multibyte:           []Multibyte{MultibyteShiftJIS, MultibyteGB18030, MultibyteBig5, MultibyteEUCKR},
+		Cutter:              true,
+		PartialCutter:       true,
+		Drawer:              true,
+		PageMode:            true,
+		RasterImage:         true,
+		Graphics:            true,
+		MaxRasterBandHeight: GS8L_MAX_Y,
+	}
+	return p.clone()
+}
+
+// built-in profiles, keyed by upper-cased model name
+var profiles = map[string]Profile{
+	"TM-T20II": {
+		Vendor: "Epson", Model: "TM-T20II",
+		PaperWidth: 576, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 48, "B": 64},
+		MaxFontSize:   8,
+		Barcodes:      gs1Barcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+	},
+	"TM-T82": {
+		Vendor: "Epson", Model: "TM-T82",
+		PaperWidth: 512, DPI: 180,
+		CharsPerLine:  map[string]int{"A": 42, "B": 56},
+		MaxFontSize:   8,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+	},
+	"TM-T88IV": {
+		Vendor: "Epson", Model: "TM-T88IV",
+		PaperWidth: 512, DPI: 180,
+		CharsPerLine:  map[string]int{"A": 42, "B": 56},
+		MaxFontSize:   8,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+	},
+	"TM-T88V": {
+		Vendor: "Epson", Model: "TM-T88V",
+		PaperWidth: 512, DPI: 180,
+		CharsPerLine:  map[string]int{"A": 42, "B": 56},
+		MaxFontSize:   8,
+		Barcodes:      gs1Barcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+		TwoColor: true,
+	},
+	"TM-T88VI": {
+		Vendor: "Epson", Model: "TM-T88VI",
+		PaperWidth: 512, DPI: 180,
+		CharsPerLine:  map[string]int{"A": 42, "B": 56},
+		MaxFontSize:   8,
+		Barcodes:      gs1Barcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode, Symbology2DDataMatrix, Symbology2DAztec},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+		GrayLevels: 16,
+		TwoColor:   true,
+	},
+	"TM-T88VII": {
+		Vendor: "Epson", Model: "TM-T88VII",
+		PaperWidth: 512, DPI: 180,
+		CharsPerLine:  map[string]int{"A": 42, "B": 56},
+		MaxFontSize:   8,
+		Barcodes:      gs1Barcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode, Symbology2DDataMatrix, Symbology2DAztec},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+		GrayLevels: 16,
+	},
+	"TM-M30": {
+		Vendor: "Epson", Model: "TM-m30",
+		PaperWidth: 576, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 48, "B": 64},
+		MaxFontSize:   8,
+		Barcodes:      gs1Barcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode, Symbology2DDataMatrix, Symbology2DAztec},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+	},
+	"TM-L90": {
+		Vendor: "Epson", Model: "TM-L90",
+		PaperWidth: 576, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 48, "B": 64},
+		MaxFontSize:   8,
+		Barcodes:      gs1Barcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode},
+		CodePages:     epsonCodePages,
+		Cutter:        true, PartialCutter: false, Drawer: true,
+		PageMode: true, RasterImage: true, Graphics: true, MaxRasterBandHeight: GS8L_MAX_Y,
+		GrayLevels: 4,
+	},
+	"TM-U220": {
+		Vendor: "Epson", Model: "TM-U220",
+		PaperWidth: 200, DPI: 80,
+		CharsPerLine: map[string]int{"A": 33, "B": 40},
+		MaxFontSize:  2,
+		CodePages:    epsonCodePages,
+		Cutter:       true, PartialCutter: true, Drawer: true,
+		PageMode: true,
+		TwoColor: true,
+	},
+	"TSP650II": {
+		Vendor: "Star", Model: "TSP650II",
+		PaperWidth: 576, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 48, "B": 64},
+		MaxFontSize:   6,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417},
+		CodePages:     westernCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		RasterImage: true,
+	},
+	"MC-PRINT3": {
+		Vendor: "Star", Model: "mC-Print3",
+		PaperWidth: 576, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 48, "B": 64},
+		MaxFontSize:   6,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417},
+		CodePages:     westernCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		RasterImage: true,
+	},
+	"XP-58": {
+		Vendor: "Xprinter", Model: "XP-58",
+		PaperWidth: 384, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 32, "B": 42},
+		MaxFontSize:   8,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode},
+		CodePages:     westernCodePages,
+		Multibyte:     []Multibyte{MultibyteGB18030},
+		Drawer:        true,
+		PageMode:      true,
+		RasterImage:   true,
+	},
+	"XP-80": {
+		Vendor: "Xprinter", Model: "XP-80",
+		PaperWidth: 576, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 48, "B": 64},
+		MaxFontSize:   8,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode},
+		CodePages:     westernCodePages,
+		Multibyte:     []Multibyte{MultibyteGB18030},
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true,
+	},
+	"SRP-350III": {
+		Vendor: "Bixolon", Model: "SRP-350III",
+		PaperWidth: 512, DPI: 180,
+		CharsPerLine:  map[string]int{"A": 42, "B": 56, "C": 64},
+		MaxFontSize:   8,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode, Symbology2DDataMatrix},
+		CodePages:     westernCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true,
+	},
+	"SRP-330II": {
+		Vendor: "Bixolon", Model: "SRP-330II",
+		PaperWidth: 576, DPI: 203,
+		CharsPerLine:  map[string]int{"A": 48, "B": 64, "C": 72},
+		MaxFontSize:   8,
+		Barcodes:      commonBarcodes,
+		Symbologies2D: []Symbology2D{Symbology2DQRCode, Symbology2DPDF417, Symbology2DMaxiCode, Symbology2DDataMatrix},
+		CodePages:     westernCodePages,
+		Cutter:        true, PartialCutter: true, Drawer: true,
+		PageMode: true, RasterImage: true,
+	},
+}
+
+// LookupProfile returns a copy of the built-in profile for the model name
+// (case insensitive, e.g. "TM-T88V").
+func LookupProfile(model string) (*Profile, bool) {
+	p, ok := profiles[strings.ToUpper(model)]
+	if !ok {
+		return nil, false
+	}
+	return p.clone(), true
+}
+
+// copy the profile, maps and slices included, so that changing the copy
+// leaves the built-in profiles alone
+func (p Profile) clone() *Profile {
+	if p.CharsPerLine != nil {
+		chars := make(map[string]int, len(p.CharsPerLine))
+		for font, n := range p.CharsPerLine {
+			chars[font] = n
+		}
+		p.CharsPerLine = chars
+	}
+	p.Barcodes = append([]BarcodeFormat(nil), p.Barcodes...)
+	p.Symbologies2D = append([]Symbology2D(nil), p.Symbologies2D...)
+	p.CodePages = append([]CodePage(nil), p.CodePages...)
+	p.Multibyte = append([]Multibyte(nil), p.Multibyte...)
+	return &p
+}
+
+// ProfileModels returns the model names of the built-in profiles.
+func ProfileModels() []string {
+	models := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		models = append(models, p.Model)
+	}
+	sort.Strings(models)
+	return models
+}
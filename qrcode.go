@@ -0,0 +1,112 @@
+package escpos
+
+import "fmt"
+
+// QRModel is the QR Code model (GS ( k function 165).
+type QRModel uint8
+
+const (
+	QRModel1 QRModel = iota + 49
+	QRModel2
+	QRModelMicro
+)
+
+// QROptions controls the appearance of QR codes. Zero fields take the printer
+// defaults: model 2, module size 3 and error correction level L.
+type QROptions struct {
+	// Model is the QR Code model.
+	Model QRModel
+
+	// ModuleSize is the width of a module in dots, 1-16.
+	ModuleSize int
+
+	// ErrorCorrection is the error correction level. Micro QR codes have
+	// no level H.
+	ErrorCorrection QRCodeErrorCorrectionLevel
+
+	// Align is the alignment, "left", "center" or "right", set with ESC a
+	// before the code and kept afterwards. Empty keeps the current one.
+	Align string
+}
+
+// longest data a QR code holds, in numeric mode
+const qrMaxData = 7089
+
+// fill in the defaults and validate the options, replacing invalid ones in
+// lenient mode
+func (e *Escpos) checkQROptions(o QROptions) (QROptions, error) {
+	if o.Model == 0 {
+		o.Model = QRModel2
+	}
+	if o.ModuleSize == 0 {
+		o.ModuleSize = 3
+	}
+	if o.ErrorCorrection == 0 {
+		o.ErrorCorrection = QRCodeErrorCorrectionLevelL
+	}
+	if o.Model < QRModel1 || o.Model > QRModelMicro {
+		if err := e.invalid("qr code model", fmt.Sprint(o.Model)); err != nil {
+			return o, err
+		}
+		o.Model = QRModel2
+	}
+	if o.ModuleSize < 1 || o.ModuleSize > 16 {
+		if err := e.invalid("qr code module size", fmt.Sprint(o.ModuleSize)); err != nil {
+			return o, err
+		}
+		o.ModuleSize = clampInt(o.ModuleSize, 1, 16)
+	}
+	maxLevel := QRCodeErrorCorrectionLevelH
+	if o.Model == QRModelMicro {
+		maxLevel = QRCodeErrorCorrectionLevelQ
+	}
+	if o.ErrorCorrection < QRCodeErrorCorrectionLevelL || o.ErrorCorrection > maxLevel {
+		if err := e.invalid("qr code error correction", fmt.Sprint(o.ErrorCorrection)); err != nil {
+			return o, err
+		}
+		o.ErrorCorrection = QRCodeErrorCorrectionLevel(clampInt(int(o.ErrorCorrection), int(QRCodeErrorCorrectionLevelL), int(maxLevel)))
+	}
+	return o, nil
+}
+
+// QRCode prints a QR code. opts may be nil for the defaults. It returns the
+// number of bytes written by the command storing the data.
+func (e *Escpos) QRCode(code string, opts *QROptions) (int, error) {
+	if len(code) == 0 || len(code) > qrMaxData {
+		return 0, &InvalidArgumentError{Command: "qr code", Value: fmt.Sprintf("%d bytes of data", len(code))}
+	}
+	if !e.profile.Supports2D(Symbology2DQRCode) {
+		return 0, e.unsupported("qr code")
+	}
+	var o QROptions
+	if opts != nil {
+		o = *opts
+	}
+	o, err := e.checkQROptions(o)
+	if err != nil {
+		return 0, err
+	}
+
+	if o.Align != "" {
+		if err := e.SetAlign(o.Align); err != nil {
+			return 0, err
+		}
+	}
+	for _, cmd := range [][]byte{
+		{GS, '(', 'k', 4, 0, 49, 65, byte(o.Model), 0},
+		{GS, '(', 'k', 3, 0, 49, 67, byte(o.ModuleSize)},
+		{GS, '(', 'k', 3, 0, 49, 69, byte(o.ErrorCorrection)},
+	} {
+		if err := e.command("qr code", cmd); err != nil {
+			return 0, err
+		}
+	}
+
+	// store the data in the symbol storage area, then print it
+	p := len(code) + 3
+	written, err := e.write("qr code", append([]byte{GS, '(', 'k', byte(p), byte(p >> 8), 49, 80, 48}, code...))
+	if err != nil {
+		return written, err
+	}
+	return written, e.command("qr code", []byte{GS, '(', 'k', 3, 0, 49, 81, 48})
+}
@@ -0,0 +1,164 @@
+package escpos
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// the commands printing a QR code, after the alignment
+func qrCommands(model QRModel, size int, level QRCodeErrorCorrectionLevel, data string) []byte {
+	p := len(data) + 3
+	cmd := []byte{
+		GS, '(', 'k', 4, 0, 49, 65, byte(model), 0,
+		GS, '(', 'k', 3, 0, 49, 67, byte(size),
+		GS, '(', 'k', 3, 0, 49, 69, byte(level),
+		GS, '(', 'k', byte(p), byte(p >> 8), 49, 80, 48,
+	}
+	cmd = append(cmd, data...)
+	return append(cmd, GS, '(', 'k', 3, 0, 49, 81, 48)
+}
+
+func TestQRCode(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  *QROptions
+		align []byte
+		want  []byte
+	}{
+		{"defaults", nil, nil,
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"zero options", &QROptions{}, nil,
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"model 1", &QROptions{Model: QRModel1}, nil,
+			qrCommands(QRModel1, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"micro", &QROptions{Model: QRModelMicro}, nil,
+			qrCommands(QRModelMicro, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"size 1", &QROptions{ModuleSize: 1}, nil,
+			qrCommands(QRModel2, 1, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"size 16", &QROptions{ModuleSize: 16}, nil,
+			qrCommands(QRModel2, 16, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"level M", &QROptions{ErrorCorrection: QRCodeErrorCorrectionLevelM}, nil,
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelM, "ABC")},
+		{"level Q", &QROptions{ErrorCorrection: QRCodeErrorCorrectionLevelQ}, nil,
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelQ, "ABC")},
+		{"level H", &QROptions{ErrorCorrection: QRCodeErrorCorrectionLevelH}, nil,
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelH, "ABC")},
+		{"micro level Q", &QROptions{Model: QRModelMicro, ErrorCorrection: QRCodeErrorCorrectionLevelQ}, nil,
+			qrCommands(QRModelMicro, 3, QRCodeErrorCorrectionLevelQ, "ABC")},
+		{"left", &QROptions{Align: "left"}, []byte{ESC, 'a', 0},
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"center", &QROptions{Align: "center"}, []byte{ESC, 'a', 1},
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"right", &QROptions{Align: "right"}, []byte{ESC, 'a', 2},
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"all", &QROptions{Model: QRModel1, ModuleSize: 6, ErrorCorrection: QRCodeErrorCorrectionLevelH, Align: "center"}, []byte{ESC, 'a', 1},
+			qrCommands(QRModel1, 6, QRCodeErrorCorrectionLevelH, "ABC")},
+	} {
+		var buf bytes.Buffer
+		e := New(&buf, nil)
+		n, err := e.QRCode("ABC", tc.opts)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		want := append(tc.align, tc.want...)
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%s: sent\n% x\nwant\n% x", tc.name, buf.Bytes(), want)
+		}
+		if n != 8+3 {
+			t.Errorf("%s: %d bytes reported written, want 11", tc.name, n)
+		}
+	}
+}
+
+func TestQRCodeLongData(t *testing.T) {
+	data := strings.Repeat("1", 300)
+	var buf bytes.Buffer
+	if _, err := New(&buf, nil).QRCode(data, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, data); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("sent % x, want % x", buf.Bytes()[:30], want[:30])
+	}
+}
+
+// invalid options are errors, or replaced by the nearest valid value in
+// lenient mode
+func TestQRCodeInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts QROptions
+		want []byte
+	}{
+		{"model", QROptions{Model: 9},
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"size 17", QROptions{ModuleSize: 17},
+			qrCommands(QRModel2, 16, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"size -1", QROptions{ModuleSize: -1},
+			qrCommands(QRModel2, 1, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"level", QROptions{ErrorCorrection: 60},
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelH, "ABC")},
+		{"level below L", QROptions{ErrorCorrection: 1},
+			qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")},
+		{"micro level H", QROptions{Model: QRModelMicro, ErrorCorrection: QRCodeErrorCorrectionLevelH},
+			qrCommands(QRModelMicro, 3, QRCodeErrorCorrectionLevelQ, "ABC")},
+		{"align", QROptions{Align: "middle"},
+			append([]byte{ESC, 'a', 0}, qrCommands(QRModel2, 3, QRCodeErrorCorrectionLevelL, "ABC")...)},
+	} {
+		var buf bytes.Buffer
+		e := New(&buf, nil)
+		opts := tc.opts
+		_, err := e.QRCode("ABC", &opts)
+		var invalid *InvalidArgumentError
+		if !errors.As(err, &invalid) {
+			t.Errorf("%s: error %v, want an InvalidArgumentError", tc.name, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: sent % x before failing", tc.name, buf.Bytes())
+		}
+
+		buf.Reset()
+		e = New(&buf, nil)
+		e.SetLenient(true)
+		if _, err := e.QRCode("ABC", &opts); err != nil {
+			t.Errorf("%s: lenient: %v", tc.name, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), tc.want) {
+			t.Errorf("%s: lenient: sent\n% x\nwant\n% x", tc.name, buf.Bytes(), tc.want)
+		}
+		if len(e.Warnings()) != 1 {
+			t.Errorf("%s: lenient: warnings %v, want one", tc.name, e.Warnings())
+		}
+	}
+}
+
+func TestQRCodeData(t *testing.T) {
+	for _, data := range []string{"", strings.Repeat("1", 7090)} {
+		var buf bytes.Buffer
+		_, err := New(&buf, nil).QRCode(data, nil)
+		var invalid *InvalidArgumentError
+		if !errors.As(err, &invalid) {
+			t.Errorf("%d bytes of data: error %v, want an InvalidArgumentError", len(data), err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%d bytes of data: sent % x", len(data), buf.Bytes())
+		}
+	}
+}
+
+func TestQRCodeUnsupported(t *testing.T) {
+	p := DefaultProfile()
+	p.Symbologies2D = nil
+	var buf bytes.Buffer
+	_, err := New(&buf, p).QRCode("ABC", nil)
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Errorf("error %v, want an UnsupportedError", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("sent % x", buf.Bytes())
+	}
+}
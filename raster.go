@@ -4,11 +4,57 @@ rasterization code extracted and converted to go from https://github.com/petrkut
 
 package escpos
 
+import (
+	"fmt"
+	"image"
+
+	"github.com/david-yappeter/escpos/raster"
+)
+
 const (
 	GS8L_MAX_Y = 1662
+	GSV0_MAX_Y = 2303
 )
 
-func (e *Escpos) Raster(width, height, bytesWidth int, img_bw []byte) {
+// DefaultImageBandHeight is the number of rows Image sends per GS v 0
+// command unless changed with SetImageBand.
+const DefaultImageBandHeight = 256
+
+// SetImageBand sets the number of rows Image sends per GS v 0 command, at
+// most GSV0_MAX_Y; zero restores DefaultImageBandHeight. between, if not
+// nil, is called between two bands of Image and of GS 8 L graphics to hold
+// back the data until the printer is ready, for instance with WaitProcessed.
+func (e *Escpos) SetImageBand(height int, between func() error) error {
+	if height < 0 || height > GSV0_MAX_Y {
+		if err := e.invalid("image band height", fmt.Sprint(height)); err != nil {
+			return err
+		}
+		height = clampInt(height, 0, GSV0_MAX_Y)
+	}
+	e.imageBand = height
+	e.betweenBands = between
+	return nil
+}
+
+// wait for the flow control between two bands
+func (e *Escpos) nextBand() error {
+	if e.betweenBands == nil {
+		return nil
+	}
+	return e.betweenBands()
+}
+
+func (e *Escpos) Raster(width, height, bytesWidth int, img_bw []byte) error {
+	if !e.profile.Graphics {
+		return e.unsupported("raster")
+	}
+	return e.graphics("raster", width, height, bytesWidth, 0x30, [][]byte{img_bw})
+}
+
+// store and print graphics of one or more planes in bands of at most
+// MaxRasterBandHeight rows. tone is 0x30 for monochrome and 0x34 for
+// multi-tone; plane i is sent as colour 0x31+i.
+func (e *Escpos) graphics(cmd string, width, height, bytesWidth int, tone byte, planes [][]byte) error {
 	flushCmd := []byte{
 		/* GS ( L, Print the graphics data in the print buffer,
 		   p. 241 Moves print position to the left side of the
@@ -20,35 +66,105 @@ func (e *Escpos) Raster(width, height, bytesWidth int, img_bw []byte) {
 	}
 
 	for l := 0; l < height; {
-		n_lines := GS8L_MAX_Y
+		if l > 0 {
+			if err := e.nextBand(); err != nil {
+				return err
+			}
+		}
+
+		n_lines := e.profile.MaxRasterBandHeight
+		if n_lines <= 0 {
+			n_lines = GS8L_MAX_Y
+		}
 		if n_lines > height-l {
 			n_lines = height - l
 		}
 
-		f112_p := 10 + n_lines*bytesWidth
-		storeCmd := []byte{
-			/* GS 8 L, Store the graphics data in the print buffer
-			   (raster format), p. 252 */
-			0x1d, 0x38, 0x4c,
-			/* p1 p2 p3 p4 */
-			byte(f112_p), byte(f112_p >> 8),
-			byte(f112_p >> 16), byte(f112_p >> 24),
-			/* Function 112 */
-			0x30, 0x70, 0x30,
-			/* bx by, zoom */
-			0x01, 0x01,
-			/* c, single-color printing model */
-			0x31,
-			/* xl, xh, number of dots in the horizontal direction */
-			byte(width), byte(width >> 8),
-			/* yl, yh, number of dots in the vertical direction */
-			byte(n_lines), byte(n_lines >> 8),
-		}
-
-		e.WriteRaw(storeCmd)
-		e.WriteRaw(img_bw[l*bytesWidth : (l+n_lines)*bytesWidth])
-		e.WriteRaw(flushCmd)
+		for i, plane := range planes {
+			f112_p := 10 + n_lines*bytesWidth
+			storeCmd := []byte{
+				/* GS 8 L, Store the graphics data in the print buffer
+				   (raster format), p. 252 */
+				0x1d, 0x38, 0x4c,
+				/* p1 p2 p3 p4 */
+				byte(f112_p), byte(f112_p >> 8),
+				byte(f112_p >> 16), byte(f112_p >> 24),
+				/* Function 112, a: monochrome or multiple tone */
+				0x30, 0x70, tone,
+				/* bx by, zoom */
+				0x01, 0x01,
+				/* c, colour or tone bit plane */
+				0x31 + byte(i),
+				/* xl, xh, number of dots in the horizontal direction */
+				byte(width), byte(width >> 8),
+				/* yl, yh, number of dots in the vertical direction */
+				byte(n_lines), byte(n_lines >> 8),
+			}
+
+			if err := e.command(cmd, storeCmd); err != nil {
+				return err
+			}
+			if err := e.command(cmd, plane[l*bytesWidth:(l+n_lines)*bytesWidth]); err != nil {
+				return err
+			}
+		}
+		if err := e.command(cmd, flushCmd); err != nil {
+			return err
+		}
 
 		l += n_lines
 	}
+	return nil
+}
+
+// RasterImage prints an image with GS 8 L graphics, converted as set by
+// SetConverter and SetDither.
+func (e *Escpos) RasterImage(img image.Image) error {
+	width, height, data := e.convert(img)
+	return e.Raster(width, height, width>>3, data)
+}
+
+// GrayImage prints an image in levels shades of gray (4 or 16) with
+// multi-tone GS 8 L graphics, scaled and dithered as set by SetConverter and
+// SetDither. Printers supporting fewer levels print the most they can;
+// printers without multi-tone graphics get a dithered black and white image.
+func (e *Escpos) GrayImage(img image.Image, levels int) error {
+	if levels != 4 && levels != 16 {
+		if err := e.invalid("gray levels", fmt.Sprint(levels)); err != nil {
+			return err
+		}
+		levels = 16
+	}
+	if levels > e.profile.GrayLevels {
+		levels = e.profile.GrayLevels
+	}
+	if levels != 4 && levels != 16 {
+		// no multi-tone graphics: dither to black and white
+		dither := e.converter.Dither
+		if dither == raster.DitherNone {
+			e.converter.Dither = raster.DitherFloydSteinberg
+			defer func() { e.converter.Dither = dither }()
+		}
+		return e.Image(img)
+	}
+
+	c, done := e.imageConverter()
+	defer done()
+	width, height, planes := c.ConvertGray(img, levels)
+	return e.graphics("gray image", width, height, width>>3, 0x34, planes)
+}
+
+// TwoColorImage prints an image on two-colour paper with GS 8 L graphics: red
+// pixels print in the second colour and the rest in black, scaled and
+// dithered as set by SetConverter and SetDither. Printers without two-colour
+// support print the whole image in black.
+func (e *Escpos) TwoColorImage(img image.Image) error {
+	if !e.profile.TwoColor || !e.profile.Graphics {
+		return e.Image(img)
+	}
+
+	c, done := e.imageConverter()
+	defer done()
+	width, height, black, red := c.ConvertTwoColor(img)
+	return e.graphics("two-colour image", width, height, width>>3, 0x30, [][]byte{black, red})
 }
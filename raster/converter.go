@@ -0,0 +1,457 @@
+package raster
+
+import (
+	"image"
+	"math"
+)
+
+// Filter is the resampling filter used to scale images.
+type Filter int
+
+const (
+	// nearest neighbour; fast, blocky when scaling up
+	FilterNearest Filter = iota
+	// bilinear interpolation of the four nearest pixels
+	FilterBilinear
+	// average of the source pixels covered; best for scaling down
+	FilterBox
+)
+
+// Align places an image narrower than MaxWidth on the line.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Printer is a destination for raster data, such as *escpos.Escpos.
+type Printer interface {
+	Raster(width, height, bytesWidth int, data []byte) error
+}
+
+// Converter turns images into printer raster data: scaled to fit the paper,
+// reduced to black and white and padded with white to whole bytes.
+//
+// The image is processed one row at a time, reading the pixel data of
+// *image.Gray, *image.RGBA, *image.NRGBA, *image.Paletted and *image.YCbCr
+// directly. Buffers are kept between calls, so a Converter reused for many
+// images of similar size does not allocate. A Converter must not be used by
+// several goroutines at once.
+type Converter struct {
+	// MaxWidth is the widest image in dots; wider images are scaled down.
+	// Zero means no limit.
+	MaxWidth int
+
+	// TargetWidth scales images to this width in dots, up or down, keeping
+	// the aspect ratio. Zero keeps the image width. MaxWidth still applies.
+	TargetWidth int
+
+	// DotWidth and DotHeight are the size of a printed dot in the dots that
+	// MaxWidth and TargetWidth count, for modes printing at a lower
+	// density: images are scaled down by them so that they keep their
+	// aspect ratio on paper. Zero means 1.
+	DotWidth, DotHeight int
+
+	// Filter is the resampling filter used when scaling.
+	Filter Filter
+
+	// Align positions the image within MaxWidth by padding it with white.
+	// AlignLeft only pads to the next whole byte.
+	Align Align
+
+	// Dither and Threshold reduce the image to black and white, see
+	// PrintImageDither.
+	Dither    Dither
+	Threshold float64
+
+	// buffers reused between calls
+	data    []byte
+	planes  [][]byte
+	red     []byte
+	row     []float32
+	src     [2][]float32
+	srcY    [2]int
+	errs    [3][]float32
+	palette [256]float32
+	bayer   [64]float32
+}
+
+// size of the scaled image in printed dots
+func (c *Converter) scaledSize(w, h int) (int, int) {
+	tw, th := w, h
+	if c.TargetWidth > 0 {
+		tw = c.TargetWidth
+	}
+	if c.MaxWidth > 0 && tw > c.MaxWidth {
+		tw = c.MaxWidth
+	}
+	if tw != w && w != 0 {
+		th = int(math.Round(float64(h) * float64(tw) / float64(w)))
+		if th < 1 {
+			th = 1
+		}
+	}
+	if c.DotWidth > 1 {
+		tw = (tw + c.DotWidth - 1) / c.DotWidth
+	}
+	if c.DotHeight > 1 {
+		th = (th + c.DotHeight - 1) / c.DotHeight
+	}
+	return tw, th
+}
+
+// padded width in dots and left offset of an image tw dots wide
+func (c *Converter) layout(tw int) (width, offset int) {
+	width = (tw + 7) &^ 7
+	maxWidth := c.MaxWidth
+	if c.DotWidth > 1 {
+		maxWidth /= c.DotWidth
+	}
+	if c.Align != AlignLeft && maxWidth > 0 {
+		if m := maxWidth &^ 7; m > width {
+			width = m
+		}
+	}
+	switch c.Align {
+	case AlignCenter:
+		offset = (width - tw) / 2
+	case AlignRight:
+		offset = width - tw
+	}
+	return width, offset
+}
+
+// Convert converts img to raster data: one bit per dot, most significant bit
+// first, 1 for black, rows of width/8 bytes. The width is a multiple of 8.
+// The returned data is overwritten by the next call to Convert.
+func (c *Converter) Convert(img image.Image) (width, height int, data []byte) {
+	width, height, planes := c.convert(img, 2)
+	if planes == nil {
+		return 0, 0, nil
+	}
+	return width, height, planes[0]
+}
+
+// ConvertGray converts img to multi-tone raster data with 4 or 16 levels of
+// gray. The darkness of a dot, 0 for white to levels-1 for black, is split
+// into bit planes laid out like Convert's data, most significant bit first:
+// 2 planes for 4 levels, 4 planes for 16. Threshold is not used; Dither
+// spreads the rounding error between neighbouring dots. The returned planes
+// are overwritten by the next conversion.
+func (c *Converter) ConvertGray(img image.Image, levels int) (width, height int, planes [][]byte) {
+	if levels != 4 && levels != 16 {
+		panic("raster: gray levels must be 4 or 16")
+	}
+	return c.convert(img, levels)
+}
+
+// convert img to levels tones, 2 meaning black and white
+func (c *Converter) convert(img image.Image, levels int) (width, height int, planes [][]byte) {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= 0 || sh <= 0 {
+		return 0, 0, nil
+	}
+
+	tw, th := c.scaledSize(sw, sh)
+	width, offset := c.layout(tw)
+	bytesWidth := width >> 3
+
+	bits := 1
+	for 1<<uint(bits) < levels {
+		bits++
+	}
+	size := bytesWidth * th
+	c.data = resize(c.data, size*bits)
+	for i := range c.data {
+		c.data[i] = 0
+	}
+	c.planes = c.planes[:0]
+	for i := 0; i < bits; i++ {
+		c.planes = append(c.planes, c.data[i*size:(i+1)*size])
+	}
+
+	c.row = resizeFloat(c.row, tw)
+	c.src[0] = resizeFloat(c.src[0], sw)
+	c.src[1] = resizeFloat(c.src[1], sw)
+	c.srcY = [2]int{-1, -1}
+	paletteLum(img, &c.palette)
+
+	threshold := c.Threshold
+	if threshold <= 0 || threshold >= 1 {
+		threshold = DefaultThreshold
+	}
+	t := float32(threshold * 255)
+	// luminance step between two tones and the rounding of dithers
+	step := 255 / float32(levels-1)
+	round := float32(0.5)
+	if levels == 2 {
+		round = 1 - t/255
+	}
+
+	kernel := diffusionKernels[c.Dither]
+	if kernel != nil {
+		for i := range c.errs {
+			c.errs[i] = resizeFloat(c.errs[i], tw+4)
+			for x := range c.errs[i] {
+				c.errs[i][x] = 0
+			}
+		}
+	}
+	n := bayerSizes[c.Dither]
+	if n > 0 {
+		m := bayerMatrix(n)
+		cells := float32(n * n)
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				// spread the rounding around the threshold
+				c.bayer[y*n+x] = round + 0.5 - (float32(m[y][x])+0.5)/cells
+			}
+		}
+	}
+
+	for y := 0; y < th; y++ {
+		c.scaledRow(img, y, sw, sh, tw, th)
+		row := y * bytesWidth
+
+		var limits []float32
+		if n > 0 {
+			limits = c.bayer[(y%n)*n : (y%n+1)*n]
+		}
+		cur, rows := c.errs[0], c.errs
+
+		for x, v := range c.row {
+			r := round
+			switch {
+			case kernel != nil:
+				v += cur[x+2]
+			case n > 0:
+				r = limits[x%n]
+			}
+
+			// index of the nearest tone from white (0) to black
+			var tone int
+			if levels == 2 && n == 0 {
+				if v < t {
+					tone = 1
+				}
+			} else {
+				tone = levels - 1 - clamp(int(v/step+r), levels-1)
+			}
+
+			if kernel != nil {
+				// the rows have two cells of padding on each side, so the
+				// error spilling over the edges needs no bounds checks
+				errv := v - float32(levels-1-tone)*step
+				for _, k := range kernel {
+					rows[k.dy][x+2+k.dx] += errv * k.weight
+				}
+			}
+
+			if tone == 0 {
+				continue
+			}
+			px := x + offset
+			i, mask := row+px>>3, byte(0x80)>>uint(px&7)
+			for p := 0; p < bits; p++ {
+				if tone&(1<<uint(bits-1-p)) != 0 {
+					c.planes[p][i] |= mask
+				}
+			}
+		}
+
+		if kernel != nil {
+			// shift the error rows up and clear the new last one
+			c.errs[0], c.errs[1], c.errs[2] = c.errs[1], c.errs[2], cur
+			for x := range cur {
+				cur[x] = 0
+			}
+		}
+	}
+
+	return width, th, c.planes
+}
+
+// Print converts img and sends it to p.
+func (c *Converter) Print(img image.Image, p Printer) error {
+	width, height, data := c.Convert(img)
+	if height == 0 {
+		return nil
+	}
+	return p.Raster(width, height, width>>3, data)
+}
+
+// fill c.row with the luminance of row y of the image scaled to tw x th
+func (c *Converter) scaledRow(img image.Image, y, sw, sh, tw, th int) {
+	if tw == sw && th == sh {
+		lumRow(img, y, c.row, &c.palette)
+		return
+	}
+
+	sx := float64(sw) / float64(tw)
+	sy := float64(sh) / float64(th)
+
+	switch c.Filter {
+	case FilterBilinear:
+		fy := (float64(y)+0.5)*sy - 0.5
+		y0 := int(math.Floor(fy))
+		ay := float32(fy - float64(y0))
+		top := c.srcRow(img, clamp(y0, sh-1))
+		bottom := c.srcRow(img, clamp(y0+1, sh-1))
+		for x := range c.row {
+			fx := (float64(x)+0.5)*sx - 0.5
+			x0 := int(math.Floor(fx))
+			ax := float32(fx - float64(x0))
+			x1 := clamp(x0+1, sw-1)
+			x0 = clamp(x0, sw-1)
+			t := top[x0]*(1-ax) + top[x1]*ax
+			b := bottom[x0]*(1-ax) + bottom[x1]*ax
+			c.row[x] = t*(1-ay) + b*ay
+		}
+	case FilterBox:
+		y0 := int(float64(y) * sy)
+		y1 := min(int(math.Ceil(float64(y+1)*sy)), sh)
+		for x := range c.row {
+			c.row[x] = 0
+		}
+		for yy := y0; yy < y1; yy++ {
+			src := c.srcRow(img, yy)
+			for x := range c.row {
+				x0 := int(float64(x) * sx)
+				x1 := min(int(math.Ceil(float64(x+1)*sx)), sw)
+				var sum float32
+				for xx := x0; xx < x1; xx++ {
+					sum += src[xx]
+				}
+				c.row[x] += sum / float32(x1-x0)
+			}
+		}
+		for x := range c.row {
+			c.row[x] /= float32(y1 - y0)
+		}
+	default:
+		src := c.srcRow(img, clamp(int((float64(y)+0.5)*sy), sh-1))
+		for x := range c.row {
+			c.row[x] = src[clamp(int((float64(x)+0.5)*sx), sw-1)]
+		}
+	}
+}
+
+// luminance of source row y, cached for the two most recent rows
+func (c *Converter) srcRow(img image.Image, y int) []float32 {
+	for i := range c.srcY {
+		if c.srcY[i] == y {
+			return c.src[i]
+		}
+	}
+	// replace the row that is not the most recent one
+	c.src[0], c.src[1] = c.src[1], c.src[0]
+	c.srcY[0], c.srcY[1] = c.srcY[1], c.srcY[0]
+	lumRow(img, y, c.src[1], &c.palette)
+	c.srcY[1] = y
+	return c.src[1]
+}
+
+// luminance weights
+const (
+	lumR = 0.299
+	lumG = 0.587
+	lumB = 0.114
+)
+
+// luminance (0-255) of 16-bit alpha-premultiplied colour on white
+func lum16(r, g, b, a uint32) float32 {
+	white := 0xffff - a
+	return (float32(r+white)*lumR + float32(g+white)*lumG + float32(b+white)*lumB) / 257
+}
+
+// fill the luminance table of a paletted image
+func paletteLum(img image.Image, lum *[256]float32) {
+	if p, ok := img.(*image.Paletted); ok {
+		for i, col := range p.Palette {
+			lum[i] = lum16(col.RGBA())
+		}
+	}
+}
+
+// fill dst with the luminance (0-255), composited onto white, of row y
+// (counted from the top of the bounds) of img
+func lumRow(img image.Image, y int, dst []float32, palette *[256]float32) {
+	b := img.Bounds()
+	y += b.Min.Y
+
+	switch m := img.(type) {
+	case *image.Gray:
+		pix := m.Pix[m.PixOffset(b.Min.X, y):]
+		for x := range dst {
+			dst[x] = float32(pix[x])
+		}
+	case *image.RGBA:
+		pix := m.Pix[m.PixOffset(b.Min.X, y):]
+		for x := range dst {
+			p := pix[x*4 : x*4+4 : x*4+4]
+			white := 255 - float32(p[3])
+			dst[x] = (float32(p[0])+white)*lumR + (float32(p[1])+white)*lumG + (float32(p[2])+white)*lumB
+		}
+	case *image.NRGBA:
+		pix := m.Pix[m.PixOffset(b.Min.X, y):]
+		for x := range dst {
+			p := pix[x*4 : x*4+4 : x*4+4]
+			a := float32(p[3]) / 255
+			v := float32(p[0])*lumR + float32(p[1])*lumG + float32(p[2])*lumB
+			dst[x] = v*a + 255*(1-a)
+		}
+	case *image.Paletted:
+		pix := m.Pix[m.PixOffset(b.Min.X, y):]
+		for x := range dst {
+			dst[x] = palette[pix[x]]
+		}
+	case *image.YCbCr:
+		// Y is the luma, computed with the same weights
+		luma := m.Y[m.YOffset(b.Min.X, y):]
+		for x := range dst {
+			dst[x] = float32(luma[x])
+		}
+	default:
+		for x := range dst {
+			dst[x] = lum16(img.At(b.Min.X+x, y).RGBA())
+		}
+	}
+}
+
+// resize a buffer, reusing its storage when large enough
+func resize(buf []byte, n int) []byte {
+	if cap(buf) < n {
+		return make([]byte, n)
+	}
+	return buf[:n]
+}
+
+// resize a float buffer, reusing its storage when large enough
+func resizeFloat(buf []float32, n int) []float32 {
+	if cap(buf) < n {
+		return make([]float32, n)
+	}
+	return buf[:n]
+}
+
+// clamp v to [0, max]
+func clamp(v, max int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// smaller of a and b
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,201 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// size of the benchmark images: a full 80mm line, a long receipt
+const benchWidth, benchHeight = 576, 2000
+
+// gray level of a test pattern pixel: diagonal gradient with stripes
+func pattern(x, y int) uint8 {
+	v := (x + y) * 255 / (benchWidth + benchHeight)
+	if (x/16+y/16)%2 == 0 {
+		v = 255 - v
+	}
+	return uint8(v)
+}
+
+// fill img with the test pattern
+func fill(img interface {
+	image.Image
+	Set(x, y int, c color.Color)
+}) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v := pattern(x-b.Min.X, y-b.Min.Y)
+			img.Set(x, y, color.NRGBA{v, v / 2, 255 - v, 255})
+		}
+	}
+}
+
+func newGray(r image.Rectangle) image.Image {
+	img := image.NewGray(r)
+	fill(img)
+	return img
+}
+
+func newRGBA(r image.Rectangle) image.Image {
+	img := image.NewRGBA(r)
+	fill(img)
+	return img
+}
+
+func newNRGBA(r image.Rectangle) image.Image {
+	img := image.NewNRGBA(r)
+	fill(img)
+	return img
+}
+
+func newPaletted(r image.Rectangle) image.Image {
+	p := make(color.Palette, 256)
+	for i := range p {
+		p[i] = color.NRGBA{uint8(i), uint8(i) / 2, 255 - uint8(i), 255}
+	}
+	img := image.NewPaletted(r, p)
+	fill(img)
+	return img
+}
+
+func newYCbCr(r image.Rectangle) image.Image {
+	img := image.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.Y[img.YOffset(x, y)] = pattern(x-b.Min.X, y-b.Min.Y)
+			img.Cb[img.COffset(x, y)] = 128 + uint8(x%32)
+			img.Cr[img.COffset(x, y)] = 128 - uint8(y%32)
+		}
+	}
+	return img
+}
+
+// an image only readable through At, as by the generic path
+type atOnly struct{ image.Image }
+
+func newAtOnly(r image.Rectangle) image.Image {
+	return atOnly{newRGBA(r)}
+}
+
+// the image types with a fast path, and the generic one
+var imageTypes = []struct {
+	name string
+	new  func(r image.Rectangle) image.Image
+}{
+	{"Gray", newGray},
+	{"RGBA", newRGBA},
+	{"NRGBA", newNRGBA},
+	{"Paletted", newPaletted},
+	{"YCbCr", newYCbCr},
+	{"At", newAtOnly},
+}
+
+func benchmarkConvert(b *testing.B, newImage func(image.Rectangle) image.Image, dither Dither) {
+	img := newImage(image.Rect(0, 0, benchWidth, benchHeight))
+	c := Converter{Dither: dither}
+	c.Convert(img)
+	b.ReportAllocs()
+	b.SetBytes(int64(benchWidth * benchHeight))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Convert(img)
+	}
+}
+
+func BenchmarkConvertGray(b *testing.B)     { benchmarkConvert(b, newGray, DitherNone) }
+func BenchmarkConvertRGBA(b *testing.B)     { benchmarkConvert(b, newRGBA, DitherNone) }
+func BenchmarkConvertNRGBA(b *testing.B)    { benchmarkConvert(b, newNRGBA, DitherNone) }
+func BenchmarkConvertPaletted(b *testing.B) { benchmarkConvert(b, newPaletted, DitherNone) }
+func BenchmarkConvertYCbCr(b *testing.B)    { benchmarkConvert(b, newYCbCr, DitherNone) }
+
+// the generic path, reading every pixel through At, for comparison
+func BenchmarkConvertAt(b *testing.B) { benchmarkConvert(b, newAtOnly, DitherNone) }
+
+func BenchmarkConvertFloydSteinberg(b *testing.B) {
+	for _, t := range imageTypes {
+		b.Run(t.name, func(b *testing.B) {
+			benchmarkConvert(b, t.new, DitherFloydSteinberg)
+		})
+	}
+}
+
+// img moved so that its bounds start at the origin, read through At
+type atOrigin struct{ image.Image }
+
+func (o atOrigin) Bounds() image.Rectangle {
+	b := o.Image.Bounds()
+	return b.Sub(b.Min)
+}
+
+func (o atOrigin) At(x, y int) color.Color {
+	m := o.Image.Bounds().Min
+	return o.Image.At(x+m.X, y+m.Y)
+}
+
+// a copy of img of the same type with its bounds at the origin, so that it
+// takes the same path through the converter
+func moved(img image.Image) image.Image {
+	b := img.Bounds()
+	r := b.Sub(b.Min)
+	var dst draw.Image
+	switch m := img.(type) {
+	case *image.Gray:
+		dst = image.NewGray(r)
+	case *image.RGBA:
+		dst = image.NewRGBA(r)
+	case *image.NRGBA:
+		dst = image.NewNRGBA(r)
+	case *image.Paletted:
+		dst = image.NewPaletted(r, m.Palette)
+	case *image.YCbCr:
+		// only the luma is read
+		y := image.NewYCbCr(r, m.SubsampleRatio)
+		for yy := 0; yy < r.Dy(); yy++ {
+			copy(y.Y[y.YOffset(0, yy):y.YOffset(0, yy)+r.Dx()], m.Y[m.YOffset(b.Min.X, b.Min.Y+yy):])
+		}
+		return y
+	default:
+		return atOrigin{img}
+	}
+	draw.Draw(dst, r, img, b.Min, draw.Src)
+	return dst
+}
+
+// images whose bounds do not start at the origin convert as the same pixels
+// at the origin do
+func TestConvertOffset(t *testing.T) {
+	converters := map[string]Converter{
+		"threshold":       {},
+		"floyd-steinberg": {Dither: DitherFloydSteinberg},
+		"bayer":           {Dither: DitherBayer4x4},
+		"bilinear":        {TargetWidth: 25, Filter: FilterBilinear},
+		"box":             {TargetWidth: 17, Filter: FilterBox, Align: AlignCenter, MaxWidth: 40},
+	}
+	for _, it := range imageTypes {
+		full := it.new(image.Rect(0, 0, 64, 48))
+		offset := it.new(image.Rect(-7, 13, 34, 45))
+		images := map[string]image.Image{
+			"SubImage": Crop(full, image.Rect(9, 5, 50, 37)),
+			"Min":      offset,
+			"Min crop": Crop(offset, image.Rect(-3, 20, 30, 41)),
+		}
+		for iname, img := range images {
+			for cname, c := range converters {
+				ref := c
+				w, h, data := c.Convert(img)
+				rw, rh, rdata := ref.Convert(moved(img))
+				if w != rw || h != rh {
+					t.Errorf("%s %s %s: size %dx%d, want %dx%d", it.name, iname, cname, w, h, rw, rh)
+					continue
+				}
+				if string(data) != string(rdata) {
+					t.Errorf("%s %s %s: data differs from the image at the origin", it.name, iname, cname)
+				}
+			}
+		}
+	}
+}
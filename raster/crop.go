@@ -0,0 +1,76 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+)
+
+// Crop returns the part of img inside r, clipped to the bounds of img. The
+// result keeps the coordinates of img, so its bounds start at r.Min rather
+// than at the origin. Images with a SubImage method, such as all the standard
+// image types, share their pixels with the result.
+func Crop(img image.Image, r image.Rectangle) image.Image {
+	r = r.Intersect(img.Bounds())
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	return &cropped{img, r}
+}
+
+// a cropped view of an image without a SubImage method
+type cropped struct {
+	image.Image
+	r image.Rectangle
+}
+
+func (c *cropped) Bounds() image.Rectangle {
+	return c.r
+}
+
+func (c *cropped) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(c.r)) {
+		return color.Transparent
+	}
+	return c.Image.At(x, y)
+}
+
+// TrimWhite crops the white margins off img: the result is the smallest part
+// of the image holding every pixel darker than the luminance threshold (0-1).
+// An image without dark pixels is cropped to nothing and prints nothing.
+func TrimWhite(img image.Image, threshold float64) image.Image {
+	if threshold <= 0 || threshold >= 1 {
+		threshold = DefaultThreshold
+	}
+	t := float32(threshold * 255)
+
+	b := img.Bounds()
+	var palette [256]float32
+	paletteLum(img, &palette)
+	row := make([]float32, b.Dx())
+
+	minX, minY, maxX, maxY := b.Dx(), b.Dy(), -1, -1
+	for y := 0; y < b.Dy(); y++ {
+		lumRow(img, y, row, &palette)
+		for x, v := range row {
+			if v >= t {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			maxY = y
+		}
+	}
+	if maxY < 0 {
+		return Crop(img, image.Rectangle{b.Min, b.Min})
+	}
+	return Crop(img, image.Rect(minX, minY, maxX+1, maxY+1).Add(b.Min))
+}
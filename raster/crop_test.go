@@ -0,0 +1,108 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCrop(t *testing.T) {
+	for _, it := range imageTypes {
+		img := it.new(image.Rect(-7, 13, 34, 45))
+		for _, tc := range []struct {
+			r, want image.Rectangle
+		}{
+			{image.Rect(0, 20, 10, 30), image.Rect(0, 20, 10, 30)},
+			{image.Rect(-20, 0, 5, 15), image.Rect(-7, 13, 5, 15)},
+			{image.Rect(100, 100, 110, 110), image.Rectangle{}},
+		} {
+			c := Crop(img, tc.r)
+			if c.Bounds() != tc.want && !(c.Bounds().Empty() && tc.want.Empty()) {
+				t.Errorf("%s: Crop(%v) bounds %v, want %v", it.name, tc.r, c.Bounds(), tc.want)
+				continue
+			}
+			for y := tc.want.Min.Y; y < tc.want.Max.Y; y++ {
+				for x := tc.want.Min.X; x < tc.want.Max.X; x++ {
+					if c.At(x, y) != img.At(x, y) {
+						t.Fatalf("%s: Crop(%v) pixel %d,%d differs", it.name, tc.r, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+// images without a SubImage method are wrapped, and so are the wrappers
+func TestCropNoSubImage(t *testing.T) {
+	img := newAtOnly(image.Rect(-7, 13, 34, 45))
+	c := Crop(img, image.Rect(0, 20, 20, 40))
+	if _, ok := c.(*cropped); !ok {
+		t.Fatalf("Crop returned %T, want *cropped", c)
+	}
+
+	cc := Crop(c, image.Rect(10, 10, 30, 30))
+	if want := image.Rect(10, 20, 20, 30); cc.Bounds() != want {
+		t.Fatalf("Crop of *cropped bounds %v, want %v", cc.Bounds(), want)
+	}
+	if cc.At(10, 20) != img.At(10, 20) {
+		t.Error("Crop of *cropped pixel differs")
+	}
+	// pixels outside the crop are not seen through it
+	if cc.At(5, 20) != color.Transparent || cc.At(25, 25) != color.Transparent {
+		t.Error("Crop of *cropped shows pixels outside its bounds")
+	}
+
+	// it converts as the same pixels at the origin do
+	var c1, c2 Converter
+	w, h, data := c1.Convert(cc)
+	rw, rh, rdata := c2.Convert(atOrigin{cc})
+	if w != rw || h != rh || string(data) != string(rdata) {
+		t.Error("Crop of *cropped converts differently from the same pixels at the origin")
+	}
+}
+
+// a white image with a black rectangle
+func blackRect(bounds, black image.Rectangle) *image.Gray {
+	img := image.NewGray(bounds)
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for y := black.Min.Y; y < black.Max.Y; y++ {
+		for x := black.Min.X; x < black.Max.X; x++ {
+			img.SetGray(x, y, color.Gray{0})
+		}
+	}
+	return img
+}
+
+func TestTrimWhite(t *testing.T) {
+	bounds := image.Rect(-7, 13, 34, 45)
+	black := image.Rect(-2, 20, 9, 31)
+	gray := blackRect(bounds, black)
+
+	for name, img := range map[string]image.Image{
+		"Gray":     gray,
+		"SubImage": gray.SubImage(image.Rect(-5, 15, 30, 40)),
+		"At":       atOnly{gray},
+	} {
+		if got := TrimWhite(img, DefaultThreshold).Bounds(); got != black {
+			t.Errorf("%s: TrimWhite bounds %v, want %v", name, got, black)
+		}
+	}
+
+	// the dark pixels at the edges are kept
+	edge := blackRect(bounds, image.Rect(-7, 13, 34, 14))
+	if got := TrimWhite(edge, DefaultThreshold).Bounds(); got != image.Rect(-7, 13, 34, 14) {
+		t.Errorf("TrimWhite of a top line bounds %v", got)
+	}
+
+	// nothing dark, nothing left
+	white := blackRect(bounds, image.Rectangle{})
+	if got := TrimWhite(white, DefaultThreshold).Bounds(); !got.Empty() {
+		t.Errorf("TrimWhite of a white image bounds %v, want empty", got)
+	}
+	var c Converter
+	if w, h, _ := c.Convert(TrimWhite(white, DefaultThreshold)); w != 0 || h != 0 {
+		t.Errorf("white image trimmed converts to %dx%d, want nothing", w, h)
+	}
+}
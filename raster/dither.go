@@ -0,0 +1,87 @@
+package raster
+
+// Dither is the algorithm used to reduce an image to black and white.
+type Dither int
+
+const (
+	// plain threshold, no dithering
+	DitherNone Dither = iota
+	DitherFloydSteinberg
+	DitherAtkinson
+	DitherJarvisJudiceNinke
+	DitherStucki
+	DitherBayer2x2
+	DitherBayer4x4
+	DitherBayer8x8
+)
+
+// DefaultThreshold is the luminance, in [0, 1], below which a pixel is
+// printed black.
+const DefaultThreshold = 0.5
+
+// one neighbour of an error diffusion kernel
+type diffusion struct {
+	dx, dy int
+	weight float32
+}
+
+// error diffusion kernels, weights already divided by the kernel divisor
+var diffusionKernels = map[Dither][]diffusion{
+	DitherFloydSteinberg: kernel(16, []diffusion{
+		{1, 0, 7},
+		{-1, 1, 3}, {0, 1, 5}, {1, 1, 1},
+	}),
+	// Atkinson only diffuses 6/8 of the error, keeping highlights crisp
+	DitherAtkinson: kernel(8, []diffusion{
+		{1, 0, 1}, {2, 0, 1},
+		{-1, 1, 1}, {0, 1, 1}, {1, 1, 1},
+		{0, 2, 1},
+	}),
+	DitherJarvisJudiceNinke: kernel(48, []diffusion{
+		{1, 0, 7}, {2, 0, 5},
+		{-2, 1, 3}, {-1, 1, 5}, {0, 1, 7}, {1, 1, 5}, {2, 1, 3},
+		{-2, 2, 1}, {-1, 2, 3}, {0, 2, 5}, {1, 2, 3}, {2, 2, 1},
+	}),
+	DitherStucki: kernel(42, []diffusion{
+		{1, 0, 8}, {2, 0, 4},
+		{-2, 1, 2}, {-1, 1, 4}, {0, 1, 8}, {1, 1, 4}, {2, 1, 2},
+		{-2, 2, 1}, {-1, 2, 2}, {0, 2, 4}, {1, 2, 2}, {2, 2, 1},
+	}),
+}
+
+// divide the kernel weights by divisor
+func kernel(divisor float32, k []diffusion) []diffusion {
+	for i := range k {
+		k[i].weight /= divisor
+	}
+	return k
+}
+
+// Bayer matrix sizes of the ordered dithers
+var bayerSizes = map[Dither]int{
+	DitherBayer2x2: 2,
+	DitherBayer4x4: 4,
+	DitherBayer8x8: 8,
+}
+
+// build the n x n Bayer index matrix, n a power of two
+func bayerMatrix(n int) [][]int {
+	m := [][]int{{0}}
+	for size := 1; size < n; size *= 2 {
+		next := make([][]int, size*2)
+		for y := range next {
+			next[y] = make([]int, size*2)
+		}
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				v := 4 * m[y][x]
+				next[y][x] = v
+				next[y][x+size] = v + 2
+				next[y+size][x] = v + 3
+				next[y+size][x+size] = v + 1
+			}
+		}
+		m = next
+	}
+	return m
+}
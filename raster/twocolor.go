@@ -0,0 +1,57 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+)
+
+// pixels whose red exceeds green and blue by this much (of 0xffff) print in
+// the second colour
+const redMin = 0x4000
+
+// report whether a colour prints red on two-colour paper
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	// the white background adds the same amount to all three channels, so
+	// the premultiplied values compare the same
+	if g > b {
+		b = g
+	}
+	return r > b && r-b >= redMin
+}
+
+// one colour of a two-colour image: the red pixels when red is set, the
+// other pixels, with the red ones turned white, when it is not
+type colorPlane struct {
+	image.Image
+	red bool
+}
+
+func (p colorPlane) ColorModel() color.Model {
+	return color.GrayModel
+}
+
+func (p colorPlane) At(x, y int) color.Color {
+	c := p.Image.At(x, y)
+	if isRed(c) {
+		if p.red {
+			return color.Black
+		}
+		return color.White
+	}
+	if p.red {
+		return color.White
+	}
+	return c
+}
+
+// ConvertTwoColor converts img for two-colour paper: red pixels go to the
+// red plane and the rest is reduced to black and white as by Convert. Both
+// planes are laid out like Convert's data and are overwritten by the next
+// conversion.
+func (c *Converter) ConvertTwoColor(img image.Image) (width, height int, black, red []byte) {
+	_, _, data := c.Convert(colorPlane{img, true})
+	c.red = append(c.red[:0], data...)
+	width, height, black = c.Convert(colorPlane{img, false})
+	return width, height, black, c.red
+}
@@ -0,0 +1,210 @@
+package escpos
+
+import (
+	"bufio"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// shape of the answers to a query, which the demux needs to tell them from
+// ASB frames
+type answerShape int32
+
+const (
+	// a single byte (DLE EOT, GS I 1-3)
+	answerByte answerShape = iota
+
+	// blocks up to a NUL (GS I 65-69, GS ( H, GS ( L)
+	answerBlock
+)
+
+// demux owns the reads from the destination from the first read with a
+// timeout or the first EnableASB on, separating ASB frames from the answers
+// to other commands
+type demux struct {
+	// answers to commands: while ASB is enabled one answer of the shape
+	// registered by the last query each, otherwise the bytes as they come
+	responses chan []byte
+
+	// decoded ASB frames; dropped when nobody listens
+	frames chan ASBStatus
+
+	// 1 while ASB is enabled, accessed atomically
+	asb int32
+
+	// answerShape expected to the last query, accessed atomically
+	expect int32
+
+	// read error that stopped the demux, set before the channels close
+	err error
+
+	// closed by Close to stop the demux
+	done chan struct{}
+}
+
+// read and sort everything the printer sends
+func (d *demux) run(r *bufio.Reader) {
+	defer close(d.frames)
+	defer close(d.responses)
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			d.err = err
+			return
+		}
+		select {
+		case <-d.done:
+			d.err = ErrClosed
+			return
+		default:
+		}
+
+		if atomic.LoadInt32(&d.asb) == 0 {
+			// no frames to look for: pass on what has been received
+			resp := make([]byte, 1+r.Buffered())
+			resp[0] = b
+			r.Read(resp[1:])
+			d.respond(resp)
+			continue
+		}
+
+		// the header of an ASB frame differs in its fixed bits from the
+		// DLE EOT status bytes and the block headers, though not from every
+		// GS I 1-3 ID
+		switch {
+		case b&0x93 == 0x10:
+			// ASB frame
+			f := [4]byte{b}
+			if _, err := io.ReadFull(r, f[1:]); err != nil {
+				d.err = err
+				return
+			}
+			select {
+			case d.frames <- decodeASB(f):
+			default:
+			}
+		case b != 0 && answerShape(atomic.LoadInt32(&d.expect)) == answerBlock:
+			block, err := r.ReadBytes(0)
+			if err != nil {
+				d.err = err
+				return
+			}
+			d.respond(append([]byte{b}, block...))
+		default:
+			d.respond([]byte{b})
+		}
+	}
+}
+
+// queue an answer, dropping the oldest one when nobody has read them, so
+// that ASB frames keep flowing
+func (d *demux) respond(resp []byte) {
+	for {
+		select {
+		case d.responses <- resp:
+			return
+		default:
+		}
+		select {
+		case <-d.responses:
+		default:
+		}
+	}
+}
+
+// start the demux, once
+func (e *Escpos) startDemux() {
+	if e.demux != nil {
+		return
+	}
+	e.demux = &demux{
+		responses: make(chan []byte, 16),
+		frames:    make(chan ASBStatus, 16),
+		done:      make(chan struct{}),
+	}
+	go e.demux.run(bufio.NewReader(e.dst))
+}
+
+// read from the printer, giving up after the read timeout. Reads with a
+// timeout start the demux, a single goroutine that keeps reading for the
+// life of the Escpos, so that a timed out read loses no later answer.
+func (e *Escpos) read(data []byte) (int, error) {
+	if e.closed {
+		return 0, ErrClosed
+	}
+	if e.demux == nil && e.readTimeout <= 0 {
+		return e.dst.Read(data)
+	}
+	e.startDemux()
+
+	if len(e.pending) == 0 {
+		var timeout <-chan time.Time
+		if e.readTimeout > 0 {
+			timer := time.NewTimer(e.readTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case resp, ok := <-e.demux.responses:
+			if !ok {
+				return 0, e.demux.err
+			}
+			e.pending = resp
+		case <-timeout:
+			return 0, ErrTimeout
+		}
+	}
+
+	n := copy(data, e.pending)
+	e.pending = e.pending[n:]
+	return n, nil
+}
+
+// Close disables Automatic Status Back and stops the goroutine that reads
+// from the printer. Callers that read from the printer or enable ASB must
+// call it before closing the destination: the goroutine ends, closing its
+// channels, once its pending read returns, at the latest when the
+// destination is closed. Reads fail with ErrClosed afterwards; commands that
+// only write still work.
+func (e *Escpos) Close() error {
+	if e.closed {
+		return nil
+	}
+	err := e.DisableASB()
+	e.closed = true
+	if e.demux != nil {
+		close(e.demux.done)
+	}
+	return err
+}
+
+// send a command the printer answers in the given shape, first dropping what
+// is left of the answers to earlier queries that timed out
+func (e *Escpos) query(cmd string, data []byte, shape answerShape) error {
+	e.discardAnswers()
+	if e.demux != nil {
+		atomic.StoreInt32(&e.demux.expect, int32(shape))
+	}
+	return e.command(cmd, data)
+}
+
+// drop the answers received but not read
+func (e *Escpos) discardAnswers() {
+	e.pending = nil
+	if e.demux == nil {
+		return
+	}
+	for {
+		select {
+		case _, ok := <-e.demux.responses:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
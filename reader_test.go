@@ -0,0 +1,160 @@
+package escpos
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// a printer answering each command written to it with answer(cmd)
+type fakePrinter struct {
+	answer func(cmd []byte) []byte
+	out    chan []byte
+	r      *io.PipeReader
+}
+
+func newFakePrinter(answer func(cmd []byte) []byte) *fakePrinter {
+	r, w := io.Pipe()
+	p := &fakePrinter{answer: answer, out: make(chan []byte, 64), r: r}
+	go func() {
+		for b := range p.out {
+			w.Write(b)
+		}
+		w.Close()
+	}()
+	return p
+}
+
+func (p *fakePrinter) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+func (p *fakePrinter) Write(b []byte) (int, error) {
+	if a := p.answer(b); len(a) > 0 {
+		p.send(a)
+	}
+	return len(b), nil
+}
+
+// send data unasked, as ASB does
+func (p *fakePrinter) send(b []byte) {
+	p.out <- b
+}
+
+// disconnect, ending reads with io.EOF
+func (p *fakePrinter) Close() error {
+	close(p.out)
+	return nil
+}
+
+// answers DLE EOT n with every fixed bit right and nothing else set
+func statusAnswer(cmd []byte) []byte {
+	if len(cmd) == 3 && cmd[0] == DLE && cmd[1] == EOT {
+		return []byte{0x12}
+	}
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	p := newFakePrinter(statusAnswer)
+	e := New(p, nil)
+	if _, err := e.PrinterStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.EnableASB(ASBAll); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.PrinterStatus(); !errors.Is(err, ErrClosed) {
+		t.Errorf("PrinterStatus after Close: %v, want ErrClosed", err)
+	}
+	if _, err := e.EnableASB(ASBAll); !errors.Is(err, ErrClosed) {
+		t.Errorf("EnableASB after Close: %v, want ErrClosed", err)
+	}
+
+	// the goroutine ends once its pending read returns
+	p.Close()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-e.demux.responses:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("demux still running after Close")
+		}
+	}
+}
+
+// a printer with model ID 0x37, which looks like a block header, sending an
+// ASB frame when ASB is enabled and before each information string
+func asbInfoAnswer(cmd []byte) []byte {
+	frame := []byte{0x14, 0x00, 0x00, 0x0f}
+	switch {
+	case len(cmd) == 3 && cmd[0] == GS && cmd[1] == 'a':
+		if cmd[2] != 0 {
+			return frame
+		}
+	case len(cmd) == 3 && cmd[0] == GS && cmd[1] == 'I':
+		switch cmd[2] {
+		case 1:
+			return []byte{0x37}
+		case 2:
+			return []byte{0x02}
+		case 3:
+			return []byte{0x41}
+		case 65:
+			return append(frame, "_1.00\x00"...)
+		case 67:
+			return append(frame, "_TM-T88V\x00"...)
+		case 66, 68, 69:
+			return []byte("_\x00")
+		}
+	}
+	return statusAnswer(cmd)
+}
+
+func TestPrinterInfoWithASB(t *testing.T) {
+	p := newFakePrinter(asbInfoAnswer)
+	e := New(p, nil)
+	defer p.Close()
+	defer e.Close()
+
+	events, err := e.EnableASB(ASBAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan int)
+	go func() {
+		n := 0
+		for range events {
+			n++
+		}
+		received <- n
+	}()
+
+	info, err := e.PrinterInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ModelID != 0x37 || info.TypeID != 0x02 || info.VersionID != 0x41 {
+		t.Errorf("IDs %#x %#x %#x, want 0x37 0x2 0x41", info.ModelID, info.TypeID, info.VersionID)
+	}
+	if info.Firmware != "1.00" || info.Model != "TM-T88V" {
+		t.Errorf("firmware %q model %q, want \"1.00\" \"TM-T88V\"", info.Firmware, info.Model)
+	}
+	if _, err := e.PrinterStatus(); err != nil {
+		t.Error(err)
+	}
+
+	if err := e.DisableASB(); err != nil {
+		t.Fatal(err)
+	}
+	if n := <-received; n == 0 {
+		t.Error("no ASB event received")
+	}
+}
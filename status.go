@@ -0,0 +1,165 @@
+package escpos
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultReadTimeout is how long reads from the printer wait for an answer
+// unless changed with SetReadTimeout.
+const DefaultReadTimeout = 2 * time.Second
+
+// ErrTimeout is returned when the printer does not answer in time.
+var ErrTimeout = errors.New("escpos: timed out waiting for the printer")
+
+// ErrClosed is returned by reads after Close.
+var ErrClosed = errors.New("escpos: closed")
+
+// SetReadTimeout sets how long ReadRaw and the status queries wait for the
+// printer to answer. Zero waits forever.
+func (e *Escpos) SetReadTimeout(d time.Duration) {
+	e.readTimeout = d
+}
+
+// PrinterStatus is the answer to DLE EOT 1.
+type PrinterStatus struct {
+	// DrawerOpen reports pin 3 of the drawer kick-out connector high, which
+	// on most drawers means the drawer is open.
+	DrawerOpen bool
+
+	// Offline reports the printer offline.
+	Offline bool
+
+	// WaitingForRecovery reports the printer waiting for online recovery.
+	WaitingForRecovery bool
+
+	// FeedButtonPressed reports the paper feed button being pressed.
+	FeedButtonPressed bool
+}
+
+// OfflineCause is the answer to DLE EOT 2.
+type OfflineCause struct {
+	// CoverOpen reports the cover open.
+	CoverOpen bool
+
+	// FeedButtonFeeding reports paper being fed with the feed button.
+	FeedButtonFeeding bool
+
+	// PaperEnd reports printing stopped because the paper ran out.
+	PaperEnd bool
+
+	// ErrorOccurred reports an error, see ErrorCause.
+	ErrorOccurred bool
+}
+
+// ErrorCause is the answer to DLE EOT 3.
+type ErrorCause struct {
+	// MechanicalError reports a recoverable error such as a paper jam.
+	MechanicalError bool
+
+	// CutterError reports an auto cutter error.
+	CutterError bool
+
+	// UnrecoverableError reports an error that needs the printer to be
+	// power-cycled.
+	UnrecoverableError bool
+
+	// AutoRecoverableError reports an error that clears by itself, such as
+	// the print head overheating.
+	AutoRecoverableError bool
+}
+
+// PaperSensorStatus is the answer to DLE EOT 4.
+type PaperSensorStatus struct {
+	// NearEnd reports the paper roll near its end.
+	NearEnd bool
+
+	// PaperEnd reports no paper.
+	PaperEnd bool
+}
+
+// query a DLE EOT status byte and check its fixed bits
+func (e *Escpos) queryStatus(n byte) (byte, error) {
+	b, err := e.ReadStatus(n)
+	if err != nil {
+		return 0, err
+	}
+	if b&0x93 != 0x12 {
+		return 0, fmt.Errorf("escpos: unexpected answer 0x%02x to DLE EOT %d", b, n)
+	}
+	return b, nil
+}
+
+// PrinterStatus queries the printer status (DLE EOT 1).
+func (e *Escpos) PrinterStatus() (PrinterStatus, error) {
+	b, err := e.queryStatus(1)
+	if err != nil {
+		return PrinterStatus{}, err
+	}
+	return PrinterStatus{
+		DrawerOpen:         b&0x04 != 0,
+		Offline:            b&0x08 != 0,
+		WaitingForRecovery: b&0x20 != 0,
+		FeedButtonPressed:  b&0x40 != 0,
+	}, nil
+}
+
+// OfflineCause queries why the printer is offline (DLE EOT 2).
+func (e *Escpos) OfflineCause() (OfflineCause, error) {
+	b, err := e.queryStatus(2)
+	if err != nil {
+		return OfflineCause{}, err
+	}
+	return OfflineCause{
+		CoverOpen:         b&0x04 != 0,
+		FeedButtonFeeding: b&0x08 != 0,
+		PaperEnd:          b&0x20 != 0,
+		ErrorOccurred:     b&0x40 != 0,
+	}, nil
+}
+
+// ErrorCause queries the error status (DLE EOT 3).
+func (e *Escpos) ErrorCause() (ErrorCause, error) {
+	b, err := e.queryStatus(3)
+	if err != nil {
+		return ErrorCause{}, err
+	}
+	return ErrorCause{
+		MechanicalError:      b&0x04 != 0,
+		CutterError:          b&0x08 != 0,
+		UnrecoverableError:   b&0x20 != 0,
+		AutoRecoverableError: b&0x40 != 0,
+	}, nil
+}
+
+// PaperSensorStatus queries the paper roll sensors (DLE EOT 4).
+func (e *Escpos) PaperSensorStatus() (PaperSensorStatus, error) {
+	b, err := e.queryStatus(4)
+	if err != nil {
+		return PaperSensorStatus{}, err
+	}
+	return PaperSensorStatus{
+		NearEnd:  b&0x0c != 0,
+		PaperEnd: b&0x60 != 0,
+	}, nil
+}
+
+// WaitProcessed waits until the printer has processed all the data sent
+// before it (GS ( H), for instance as flow control between image bands with
+// SetImageBand. It gives up after the read timeout.
+func (e *Escpos) WaitProcessed() error {
+	e.processID++
+	id := []byte(fmt.Sprintf("%04d", e.processID%10000))
+	if err := e.query("process id", append([]byte{GS, '(', 'H', 6, 0, 48, 48}, id...), answerBlock); err != nil {
+		return err
+	}
+	block, err := e.readBlock()
+	if err != nil {
+		return err
+	}
+	if len(block) != 6 || block[0] != 0x37 || block[1] != 0x22 || string(block[2:]) != string(id) {
+		return fmt.Errorf("escpos: unexpected answer %q to process id %s", block, id)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package escpos
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/david-yappeter/escpos/raster"
+)
+
+// character cell of each font in dots, width x height
+var fontCells = map[uint8][2]int{
+	0: {12, 24},
+	1: {9, 17},
+	2: {9, 17},
+}
+
+// DefineChar defines a user-defined character (ESC &) in slot code (32-126)
+// for the current font and prints it whenever Write meets r. The glyph image
+// must fit the character cell of the font: 12x24 dots for font A, 9x17 for
+// fonts B and C. Dark pixels are printed.
+func (e *Escpos) DefineChar(r rune, code byte, glyph image.Image) error {
+	if code < 32 || code > 126 {
+		return &InvalidArgumentError{Command: "user-defined character", Value: fmt.Sprint(code)}
+	}
+
+	cell := fontCells[e.font]
+	b := glyph.Bounds()
+	if b.Dx() > cell[0] || b.Dy() > cell[1] {
+		if err := e.invalid("user-defined character", fmt.Sprintf("%dx%d glyph", b.Dx(), b.Dy())); err != nil {
+			return err
+		}
+		glyph = raster.Crop(glyph, image.Rectangle{b.Min, b.Min.Add(image.Pt(cell[0], cell[1]))})
+	}
+
+	c := raster.Converter{Threshold: e.converter.Threshold}
+	width, height, data := c.Convert(glyph)
+	bytesWidth := width >> 3
+	if height == 0 {
+		width = 0
+	} else {
+		// the converter pads the width to whole bytes
+		width = glyph.Bounds().Dx()
+	}
+
+	// columns of three bytes, top dot in the most significant bit
+	cmd := []byte{ESC, '&', 3, code, code, byte(width)}
+	for x := 0; x < width; x++ {
+		var col [3]byte
+		for y := 0; y < height; y++ {
+			if data[y*bytesWidth+x>>3]&(0x80>>uint(x&7)) != 0 {
+				col[y>>3] |= 0x80 >> uint(y&7)
+			}
+		}
+		cmd = append(cmd, col[:]...)
+	}
+
+	if err := e.command("user-defined character", cmd); err != nil {
+		return err
+	}
+	if e.userChars == nil {
+		e.userChars = make(map[rune]byte)
+	}
+	e.userChars[r] = code
+	return nil
+}
+
+// DeleteChar deletes the user-defined character printed for r (ESC ?).
+func (e *Escpos) DeleteChar(r rune) error {
+	code, ok := e.userChars[r]
+	if !ok {
+		return nil
+	}
+	if err := e.userCharMode(false); err != nil {
+		return err
+	}
+	delete(e.userChars, r)
+	return e.command("delete user-defined character", []byte{ESC, '?', code})
+}
+
+// select or cancel the user-defined character set (ESC %)
+func (e *Escpos) userCharMode(on bool) error {
+	if e.userCharsOn == on {
+		return nil
+	}
+	cmd := []byte{ESC, '%', 0}
+	if on {
+		cmd[2] = 1
+	}
+	if err := e.command("user-defined characters", cmd); err != nil {
+		return err
+	}
+	e.userCharsOn = on
+	return nil
+}